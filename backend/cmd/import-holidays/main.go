@@ -0,0 +1,73 @@
+// import-holidays は大学の学年暦（iCalendar）の終日イベントを休日に取り込むコマンド
+//
+//	go run ./cmd/import-holidays -file calendar.ics          # 追加される日の確認のみ
+//	go run ./cmd/import-holidays -file calendar.ics -apply   # 取り込む
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/kasa021/watabe-lab-app/internal/config"
+	"github.com/kasa021/watabe-lab-app/internal/database"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/service"
+)
+
+func main() {
+	path := flag.String("file", "", "取り込む iCalendar ファイル (.ics)")
+	apply := flag.Bool("apply", false, "差分を確認するだけでなく休日に取り込む")
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	cfg := config.Load()
+	labLoc, err := cfg.Lab.Location()
+	if err != nil {
+		log.Fatalf("Invalid lab time zone: %v", err)
+	}
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *path, err)
+	}
+	defer file.Close()
+
+	attendanceRepo := repository.NewAttendanceRepository(db)
+	settingsRepo := repository.NewSettingsRepository(db)
+	dailyAttendanceService := service.NewDailyAttendanceService(attendanceRepo, settingsRepo, labLoc)
+	// サーバーのランキングのキャッシュは有効期限が切れると反映される
+	holidayService := service.NewHolidayService(settingsRepo, dailyAttendanceService, nil, labLoc)
+
+	result, err := holidayService.ImportICal(context.Background(), 0, file, *apply)
+	if err != nil {
+		log.Fatalf("Failed to import holidays: %v", err)
+	}
+
+	for _, h := range result.Added {
+		fmt.Printf("+ %s %s\n", h.Date, h.Summary)
+	}
+	fmt.Printf("%d day(s) to add, %d already registered, %d timed event(s) skipped\n",
+		len(result.Added), result.AlreadyRegistered, result.SkippedEvents)
+	switch {
+	case result.Applied:
+		fmt.Println("Applied.")
+	case len(result.Added) > 0:
+		fmt.Println("Dry run. Re-run with -apply to import.")
+	}
+}
@@ -0,0 +1,68 @@
+// recompute-achievements は出席履歴から称号を判定し直し、付与漏れの称号を条件を満たした日時で付与するコマンド
+//
+//	go run ./cmd/recompute-achievements                   # 付与される称号の確認のみ
+//	go run ./cmd/recompute-achievements -user 3 -apply    # ユーザー3に付与する
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/joho/godotenv"
+	"github.com/kasa021/watabe-lab-app/internal/config"
+	"github.com/kasa021/watabe-lab-app/internal/database"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/service"
+)
+
+func main() {
+	userID := flag.Uint("user", 0, "対象のユーザーID (省略時は全ユーザー)")
+	apply := flag.Bool("apply", false, "付与される称号を確認するだけでなく付与する")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	cfg := config.Load()
+	labLoc, err := cfg.Lab.Location()
+	if err != nil {
+		log.Fatalf("Invalid lab time zone: %v", err)
+	}
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	achievementService := service.NewAchievementService(
+		repository.NewAchievementRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewAttendanceRepository(db),
+		repository.NewSettingsRepository(db),
+		repository.NewPointRepository(db),
+		nil, // 手動付与は行わないため WebSocket の配信先は無い
+		labLoc,
+	)
+
+	// サーバーのランキングのキャッシュは有効期限が切れると反映される
+	result, err := achievementService.Recompute(context.Background(), uint(*userID), *apply)
+	if result != nil {
+		for _, u := range result.Unlocks {
+			fmt.Printf("+ %s %s (%s) +%dpt at %s\n",
+				u.Username, u.AchievementName, u.AchievementCode, u.PointsReward, u.AchievedAt.In(labLoc).Format("2006-01-02 15:04"))
+		}
+	}
+	if err != nil {
+		log.Fatalf("Failed to recompute achievements: %v", err)
+	}
+
+	fmt.Printf("%d achievement(s) to unlock for %d user(s) checked\n", len(result.Unlocks), result.UsersChecked)
+	switch {
+	case result.Applied && len(result.Unlocks) > 0:
+		fmt.Println("Applied.")
+	case len(result.Unlocks) > 0:
+		fmt.Println("Dry run. Re-run with -apply to unlock.")
+	}
+}
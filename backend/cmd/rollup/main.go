@@ -0,0 +1,68 @@
+// rollup は check_in_logs から daily_attendances を再計算するコマンド
+//
+//	go run ./cmd/rollup -from 2025-04-01 -to 2026-03-31
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/kasa021/watabe-lab-app/internal/config"
+	"github.com/kasa021/watabe-lab-app/internal/database"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/service"
+)
+
+func main() {
+	fromStr := flag.String("from", "", "再計算の開始日 (YYYY-MM-DD, 省略時は今日)")
+	toStr := flag.String("to", "", "再計算の終了日 (YYYY-MM-DD, この日を含む, 省略時は今日)")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	cfg := config.Load()
+	labLoc, err := cfg.Lab.Location()
+	if err != nil {
+		log.Fatalf("Invalid lab time zone: %v", err)
+	}
+
+	// 日付は研究室のタイムゾーンで解釈する
+	today := time.Now().In(labLoc).Format("2006-01-02")
+	if *fromStr == "" {
+		*fromStr = today
+	}
+	if *toStr == "" {
+		*toStr = today
+	}
+	from, err := time.ParseInLocation("2006-01-02", *fromStr, labLoc)
+	if err != nil {
+		log.Fatalf("Invalid -from: %v", err)
+	}
+	to, err := time.ParseInLocation("2006-01-02", *toStr, labLoc)
+	if err != nil {
+		log.Fatalf("Invalid -to: %v", err)
+	}
+	if to.Before(from) {
+		log.Fatalf("-to must not be before -from")
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	attendanceRepo := repository.NewAttendanceRepository(db)
+	settingsRepo := repository.NewSettingsRepository(db)
+	dailyAttendanceService := service.NewDailyAttendanceService(attendanceRepo, settingsRepo, labLoc)
+
+	written, err := dailyAttendanceService.Rebuild(context.Background(), from, to)
+	if err != nil {
+		log.Fatalf("Failed to rebuild daily attendances: %v", err)
+	}
+	log.Printf("Rebuilt %d daily attendance row(s) from %s to %s", written, *fromStr, *toStr)
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -13,6 +17,7 @@ import (
 	"github.com/kasa021/watabe-lab-app/internal/handler"
 	"github.com/kasa021/watabe-lab-app/internal/middleware"
 	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/scheduler"
 	"github.com/kasa021/watabe-lab-app/internal/service"
 	"github.com/kasa021/watabe-lab-app/internal/ws"
 )
@@ -27,6 +32,12 @@ func main() {
 	cfg := config.Load()
 	log.Printf("Server starting in %s mode", cfg.Server.Env)
 
+	// 研究室のタイムゾーン（日付の区切り・ランキング期間の基準）
+	labLoc, err := cfg.Lab.Location()
+	if err != nil {
+		log.Fatalf("Invalid lab time zone: %v", err)
+	}
+
 	// データベース接続
 	db, err := database.NewDatabase(cfg)
 	if err != nil {
@@ -45,22 +56,73 @@ func main() {
 	// WebSocket Hubの初期化と起動
 	hub := ws.NewHub()
 	go hub.Run()
+	wsTickets := ws.NewTicketStore(30 * time.Second)
 
 	// 実績管理機能の初期化
+	attendanceRepo := repository.NewAttendanceRepository(db)
 	achievementRepo := repository.NewAchievementRepository(db)
-	achievementService := service.NewAchievementService(achievementRepo, userRepo)
-	achievementHandler := handler.NewAchievementHandler(achievementService)
+	settingsRepo := repository.NewSettingsRepository(db)
+	pointRepo := repository.NewPointRepository(db)
+	achievementService := service.NewAchievementService(achievementRepo, userRepo, attendanceRepo, settingsRepo, pointRepo, hub, labLoc)
+
+	// ランキング（集計のキャッシュは出席記録の変更時に無効にする）
+	rankingService := service.NewRankingService(attendanceRepo, pointRepo, settingsRepo, labLoc)
+	achievementHandler := handler.NewAchievementHandler(achievementService, rankingService)
+
+	// 称号マスタの管理（アップロードされたアイコンは /api/v1/achievement-icons/ で配信する）
+	achievementIconDir := filepath.Join(cfg.Server.UploadDir, "achievement-icons")
+	achievementAdminService := service.NewAchievementAdminService(achievementRepo, achievementIconDir, "/api/v1/achievement-icons/")
+	adminAchievementHandler := handler.NewAdminAchievementHandler(achievementAdminService)
+	rankingUpdateNotifier := service.NewRankingUpdateNotifier(rankingService, userRepo, hub)
+	// 起動時点のランキングを配信済みとして記録し、再起動後の最初の配信で全ての行を送らないようにする
+	if err := rankingUpdateNotifier.Seed(context.Background()); err != nil {
+		log.Printf("Failed to seed ranking updates: %v", err)
+	}
 
 	// 出席管理機能の初期化
-	// 出席管理機能の初期化
-	attendanceRepo := repository.NewAttendanceRepository(db)
-	settingsRepo := repository.NewSettingsRepository(db)                                                     // Added
-	attendanceService := service.NewAttendanceService(attendanceRepo, settingsRepo, hub, achievementService) // Updated
+	dailyAttendanceService := service.NewDailyAttendanceService(attendanceRepo, settingsRepo, labLoc)
+	attendanceService := service.NewAttendanceService(attendanceRepo, settingsRepo, hub, achievementService, dailyAttendanceService, rankingService, rankingUpdateNotifier, labLoc)
 	attendanceHandler := handler.NewAttendanceHandler(attendanceService)
+	attendanceAdminService := service.NewAttendanceAdminService(attendanceRepo, userRepo, dailyAttendanceService, achievementService, rankingService, hub)
+	adminAttendanceHandler := handler.NewAdminAttendanceHandler(attendanceAdminService)
+
+	// ポイント
+	pointService := service.NewPointService(pointRepo)
+	pointHandler := handler.NewPointHandler(pointService)
+
+	// 休日カレンダー
+	holidayService := service.NewHolidayService(settingsRepo, dailyAttendanceService, rankingService, labLoc)
+	holidayHandler := handler.NewHolidayHandler(holidayService)
+
+	// 出席記録の訂正申請
+	correctionRequestRepo := repository.NewCorrectionRequestRepository(db)
+	correctionRequestService := service.NewCorrectionRequestService(correctionRequestRepo, attendanceRepo, attendanceAdminService, hub)
+	correctionRequestHandler := handler.NewCorrectionRequestHandler(correctionRequestService)
+
+	// 定期実行ジョブの起動
+	sched := scheduler.NewScheduler()
+	sched.Register("auto_checkout", time.Minute, func(ctx context.Context) error {
+		closed, err := attendanceService.AutoCheckOut(ctx, time.Now())
+		if closed > 0 {
+			log.Printf("Auto checked out %d session(s)", closed)
+		}
+		return err
+	})
 
 	// ランキング機能の初期化
-	rankingService := service.NewRankingService(attendanceRepo)
-	rankingHandler := handler.NewRankingHandler(rankingService)
+	rankingSnapshotRepo := repository.NewRankingSnapshotRepository(db)
+	rankingSnapshotService := service.NewRankingSnapshotService(rankingSnapshotRepo, attendanceRepo, settingsRepo, labLoc)
+	rankingHandler := handler.NewRankingHandler(rankingService, rankingSnapshotService, userRepo)
+
+	// 締めた週・月のランキングを保存する（保存済みの期間は何もしない）
+	sched.Register("ranking_snapshot", time.Hour, func(ctx context.Context) error {
+		saved, err := rankingSnapshotService.SnapshotClosedPeriods(ctx, time.Now())
+		if saved > 0 {
+			log.Printf("Saved %d ranking snapshot(s)", saved)
+		}
+		return err
+	})
+	sched.Start(context.Background())
 
 	// Ginエンジンの作成
 	if cfg.Server.Env == "production" {
@@ -68,6 +130,19 @@ func main() {
 	}
 	r := gin.Default()
 
+	// クライアントIPの判定設定
+	// プロキシを信頼しないと、nginx 経由のリクエストは全て nginx のアドレスから来たことになり、
+	// IP制限付きチェックインが誰も（許可リストに nginx が入っていれば誰でも）通るようになる
+	if len(cfg.Server.TrustedProxies) == 0 {
+		if cfg.Server.Env == "production" {
+			log.Fatal("TRUSTED_PROXIES is required in production (set it to the reverse proxy address, or 127.0.0.1 if there is none)")
+		}
+		log.Println("WARNING: TRUSTED_PROXIES is not set; client IPs behind a reverse proxy will be the proxy address")
+	}
+	if err := middleware.ConfigureClientIP(r, cfg.Server.TrustedProxies, cfg.Server.ForwardedHeader); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// CORS設定
 	corsConfig := cors.DefaultConfig()
 	if allowedOrigins := os.Getenv("ALLOWED_ORIGINS"); allowedOrigins != "" {
@@ -78,6 +153,7 @@ func main() {
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
 	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
 	corsConfig.AllowCredentials = true
+	corsConfig.ExposeHeaders = []string{handler.HeaderRankingCacheAge}
 	r.Use(cors.New(corsConfig))
 
 	// APIルーティング
@@ -97,9 +173,16 @@ func main() {
 			})
 		})
 
+		// 称号のアイコン
+		api.Static("/achievement-icons", achievementIconDir)
+
 		// WebSocket エンドポイント
+		// ブラウザのWebSocketはヘッダーを付けられず、JWTをクエリに載せるとアクセスログに残るため、
+		// POST /ws/ticket で発行した使い捨てのチケットを ?ticket= で受け取る。
+		// チケットが無い・無効な場合も全体向けのイベントは受信できる（本人宛の通知は届かない）
 		api.GET("/ws", func(c *gin.Context) {
-			ws.ServeWs(hub, c)
+			userID, _ := wsTickets.Redeem(c.Query("ticket"))
+			ws.ServeWs(hub, c, userID)
 		})
 
 		// 認証エンドポイント（認証不要）
@@ -114,18 +197,32 @@ func main() {
 		{
 			protected.GET("/auth/me", authHandler.Me)
 
+			// WebSocket 接続用のチケット（30秒以内に1回だけ使える）
+			protected.POST("/ws/ticket", func(c *gin.Context) {
+				ticket, err := wsTickets.Issue(c.GetUint("user_id"))
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue ticket"})
+					return
+				}
+				c.JSON(http.StatusOK, gin.H{"ticket": ticket})
+			})
+
 			// 出席管理エンドポイント
 			attendance := protected.Group("/attendance")
 			{
 				attendance.POST("/checkin", attendanceHandler.CheckIn)
 				attendance.POST("/checkout", attendanceHandler.CheckOut)
 				attendance.GET("/active", attendanceHandler.GetActiveUsers)
+				attendance.POST("/corrections", correctionRequestHandler.Submit)
+				attendance.GET("/corrections/my", correctionRequestHandler.GetMyRequests)
 			}
 
 			// ランキングエンドポイント
 			rankings := protected.Group("/rankings")
 			{
 				rankings.GET("", rankingHandler.GetRankings)
+				rankings.GET("/history", rankingHandler.GetSnapshotPeriods)
+				rankings.GET("/history/:from", rankingHandler.GetSnapshot)
 			}
 
 			// 実績エンドポイント
@@ -133,20 +230,62 @@ func main() {
 			{
 				achievements.GET("", achievementHandler.GetAchievements)
 				achievements.GET("/my", achievementHandler.GetMyAchievements)
+				achievements.GET("/my/progress", achievementHandler.GetMyProgress)
 			}
-			// ユーザーごとの実績
-			protected.GET("/users/:id/achievements", achievementHandler.GetUserAchievements)
-
-			// ユーザープロフィール・ヒートマップ
-			userHandler := handler.NewUserHandler(userRepo, attendanceRepo)
+			// ユーザープロフィール
+			userHandler := handler.NewUserHandler(userRepo, attendanceRepo, attendanceService, rankingService)
 			protected.PUT("/users/me", userHandler.UpdateProfile)
-			protected.GET("/users/:id/heatmap", userHandler.GetAttendanceHeatmap)
 
-			// 管理者のみアクセス可能なエンドポイント
-			admin := protected.Group("")
-			admin.Use(middleware.RoleMiddleware("admin"))
+			// ユーザーごとの出席データ（公開範囲のチェックあり、:id は "me" も可）
+			users := protected.Group("/users/:id")
+			users.Use(middleware.UserVisibilityMiddleware(userRepo))
 			{
-				// TODO: 管理者用エンドポイント
+				users.GET("/achievements", achievementHandler.GetUserAchievements)
+				users.GET("/heatmap", userHandler.GetAttendanceHeatmap)
+				users.GET("/sessions", userHandler.GetSessions)
+				users.GET("/points", pointHandler.GetBalance)
+				users.GET("/ranking-history", rankingHandler.GetUserHistory)
+			}
+
+			// 管理者・教員のみアクセス可能なエンドポイント
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RoleMiddleware("admin", "teacher"))
+			{
+				// 出席記録の修正
+				admin.POST("/attendance/sessions", adminAttendanceHandler.CreateSession)
+				admin.PUT("/attendance/sessions/:id", adminAttendanceHandler.UpdateSession)
+				admin.DELETE("/attendance/sessions/:id", adminAttendanceHandler.DeleteSession)
+				admin.GET("/attendance/audit-logs", adminAttendanceHandler.GetAuditLogs)
+
+				// 訂正申請の承認・却下
+				admin.GET("/attendance/corrections", correctionRequestHandler.GetRequests)
+				admin.POST("/attendance/corrections/:id/approve", correctionRequestHandler.Approve)
+				admin.POST("/attendance/corrections/:id/reject", correctionRequestHandler.Reject)
+
+				// 休日カレンダー（:year は年度）
+				admin.GET("/holidays/:year", holidayHandler.GetHolidays)
+				admin.PUT("/holidays/:year", holidayHandler.SetHolidays)
+				admin.POST("/holidays/import", holidayHandler.ImportICal)
+
+				// 称号マスタの一覧と再計算
+				admin.GET("/achievements", adminAchievementHandler.ListAchievements)
+				admin.POST("/achievements/recompute", achievementHandler.Recompute)
+
+				// 称号の手動付与・取り消し（condition_type が manual の称号のみ）
+				admin.POST("/achievements/:id/awards", achievementHandler.Award)
+				admin.DELETE("/achievements/:id/awards/:user_id", achievementHandler.Revoke)
+				admin.GET("/achievements/award-logs", achievementHandler.GetAwardLogs)
+
+				// 称号マスタの編集（管理者のみ）
+				adminOnly := admin.Group("")
+				adminOnly.Use(middleware.RoleMiddleware("admin"))
+				{
+					adminOnly.POST("/achievements", adminAchievementHandler.CreateAchievement)
+					adminOnly.PUT("/achievements/order", adminAchievementHandler.ReorderAchievements)
+					adminOnly.PUT("/achievements/:id", adminAchievementHandler.UpdateAchievement)
+					adminOnly.DELETE("/achievements/:id", adminAchievementHandler.DeleteAchievement)
+					adminOnly.POST("/achievements/:id/icon", adminAchievementHandler.UploadIcon)
+				}
 			}
 		}
 	}
@@ -0,0 +1,2 @@
+-- 自動チェックアウトフラグの削除
+ALTER TABLE check_in_logs DROP COLUMN IF EXISTS is_auto_checkout;
@@ -0,0 +1,5 @@
+-- 自動チェックアウトフラグの追加
+ALTER TABLE check_in_logs
+ADD COLUMN IF NOT EXISTS is_auto_checkout BOOLEAN NOT NULL DEFAULT false;
+-- コメント
+COMMENT ON COLUMN check_in_logs.is_auto_checkout IS '自動チェックアウトにより終了したか';
@@ -0,0 +1,4 @@
+-- 出席記録の修正履歴テーブルの削除
+DROP INDEX IF EXISTS idx_attendance_audit_logs_check_in_log_id;
+DROP INDEX IF EXISTS idx_attendance_audit_logs_user_id;
+DROP TABLE IF EXISTS attendance_audit_logs;
@@ -0,0 +1,24 @@
+-- 出席記録の修正履歴テーブルの作成
+CREATE TABLE IF NOT EXISTS attendance_audit_logs (
+    id SERIAL PRIMARY KEY,
+    check_in_log_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    before_value JSONB,
+    after_value JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- インデックスの作成
+CREATE INDEX idx_attendance_audit_logs_user_id ON attendance_audit_logs(user_id);
+CREATE INDEX idx_attendance_audit_logs_check_in_log_id ON attendance_audit_logs(check_in_log_id);
+-- コメント
+COMMENT ON TABLE attendance_audit_logs IS '出席記録の修正履歴';
+COMMENT ON COLUMN attendance_audit_logs.check_in_log_id IS '対象のチェックインログID（削除済みの場合も保持）';
+COMMENT ON COLUMN attendance_audit_logs.user_id IS '対象ユーザーID';
+COMMENT ON COLUMN attendance_audit_logs.actor_id IS '操作したユーザーID';
+COMMENT ON COLUMN attendance_audit_logs.action IS '操作（create/update/delete）';
+COMMENT ON COLUMN attendance_audit_logs.reason IS '修正理由';
+COMMENT ON COLUMN attendance_audit_logs.before_value IS '修正前のログ（JSON形式）';
+COMMENT ON COLUMN attendance_audit_logs.after_value IS '修正後のログ（JSON形式）';
@@ -0,0 +1,4 @@
+-- 出席記録の訂正申請テーブルの削除
+DROP INDEX IF EXISTS idx_attendance_correction_requests_status;
+DROP INDEX IF EXISTS idx_attendance_correction_requests_user_id;
+DROP TABLE IF EXISTS attendance_correction_requests;
@@ -0,0 +1,27 @@
+-- 出席記録の訂正申請テーブルの作成
+CREATE TABLE IF NOT EXISTS attendance_correction_requests (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    check_in_log_id INTEGER REFERENCES check_in_logs(id) ON DELETE SET NULL,
+    request_type VARCHAR(20) NOT NULL,
+    check_in_at TIMESTAMP NOT NULL,
+    check_out_at TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reviewer_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    review_comment TEXT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- インデックスの作成
+CREATE INDEX idx_attendance_correction_requests_user_id ON attendance_correction_requests(user_id);
+CREATE INDEX idx_attendance_correction_requests_status ON attendance_correction_requests(status);
+-- コメント
+COMMENT ON TABLE attendance_correction_requests IS '出席記録の訂正申請';
+COMMENT ON COLUMN attendance_correction_requests.check_in_log_id IS '訂正対象のチェックインログID（missing の場合は承認時に作成されたログ）';
+COMMENT ON COLUMN attendance_correction_requests.request_type IS '申請種別（missing: 記録漏れ / modify: 記録の誤り）';
+COMMENT ON COLUMN attendance_correction_requests.check_in_at IS '申請するチェックイン日時';
+COMMENT ON COLUMN attendance_correction_requests.check_out_at IS '申請するチェックアウト日時';
+COMMENT ON COLUMN attendance_correction_requests.status IS '状態（pending/approved/rejected）';
+COMMENT ON COLUMN attendance_correction_requests.reviewer_id IS '承認・却下したユーザーID';
@@ -0,0 +1,2 @@
+-- 出席データの公開範囲の削除
+ALTER TABLE users DROP COLUMN IF EXISTS profile_visibility;
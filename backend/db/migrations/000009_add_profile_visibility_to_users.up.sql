@@ -0,0 +1,7 @@
+-- 出席データの公開範囲の追加
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS profile_visibility VARCHAR(20) NOT NULL DEFAULT 'members';
+-- 在室状態を非公開にしていたユーザーは出席データも非公開にする
+UPDATE users SET profile_visibility = 'private' WHERE is_presence_public = false;
+-- コメント
+COMMENT ON COLUMN users.profile_visibility IS '出席データ（ヒートマップ・履歴・称号・ランキング）の公開範囲（public/members/private）';
@@ -0,0 +1,32 @@
+-- 日時列をタイムゾーン付き（TIMESTAMPTZ）に変更する
+-- これまでアプリケーション（UTCで動作）が書き込んだ日時はUTCとして解釈する。
+-- 旧 seed_attendance_heatmap.go は DSN に TimeZone=Asia/Tokyo を指定して研究室の時刻で書き込んでいたため、
+-- その行はこの変換で9時間ずれる。既存環境での補正方法は README の「既存環境のタイムゾーン移行」を参照。
+-- daily_attendances の日時はDBの NOW() で書き込まれているため、DBセッションのタイムゾーンとして解釈する。
+ALTER TABLE users
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC',
+    ALTER COLUMN last_login_at TYPE TIMESTAMPTZ USING last_login_at AT TIME ZONE 'UTC';
+ALTER TABLE check_in_logs
+    ALTER COLUMN check_in_at TYPE TIMESTAMPTZ USING check_in_at AT TIME ZONE 'UTC',
+    ALTER COLUMN check_out_at TYPE TIMESTAMPTZ USING check_out_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE daily_attendances
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+ALTER TABLE achievements
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE user_achievements
+    ALTER COLUMN achieved_at TYPE TIMESTAMPTZ USING achieved_at AT TIME ZONE 'UTC';
+ALTER TABLE settings
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE attendance_audit_logs
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+ALTER TABLE attendance_correction_requests
+    ALTER COLUMN check_in_at TYPE TIMESTAMPTZ USING check_in_at AT TIME ZONE 'UTC',
+    ALTER COLUMN check_out_at TYPE TIMESTAMPTZ USING check_out_at AT TIME ZONE 'UTC',
+    ALTER COLUMN reviewed_at TYPE TIMESTAMPTZ USING reviewed_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
@@ -0,0 +1,6 @@
+-- ポイント台帳・ポイント取引テーブルの削除
+DROP VIEW IF EXISTS point_ledger;
+DROP INDEX IF EXISTS idx_point_transactions_achievement;
+DROP INDEX IF EXISTS idx_point_transactions_user_id;
+DROP TABLE IF EXISTS point_transactions;
+COMMENT ON COLUMN daily_attendances.points IS '獲得ポイント（1 or 0）';
@@ -0,0 +1,47 @@
+-- ポイント取引テーブルの作成（称号の報酬など、出席以外で増減するポイント）
+CREATE TABLE IF NOT EXISTS point_transactions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    amount INTEGER NOT NULL,
+    achievement_id INTEGER REFERENCES achievements(id) ON DELETE SET NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- インデックスの作成
+CREATE INDEX idx_point_transactions_user_id ON point_transactions(user_id);
+-- 同じ称号の報酬は1回だけ
+CREATE UNIQUE INDEX idx_point_transactions_achievement ON point_transactions(user_id, achievement_id)
+WHERE source = 'achievement';
+-- 獲得済みの称号の報酬を付与する
+INSERT INTO point_transactions (user_id, source, amount, achievement_id, description, created_at)
+SELECT ua.user_id, 'achievement', a.points_reward, a.id, a.name, ua.achieved_at
+FROM user_achievements ua
+JOIN achievements a ON a.id = ua.achievement_id
+WHERE a.points_reward > 0;
+-- ポイント台帳（日次の出席ポイントとポイント取引を合わせたもの）
+CREATE OR REPLACE VIEW point_ledger AS
+SELECT user_id,
+    'attendance' AS source,
+    points AS amount,
+    attendance_date,
+    NULL::INTEGER AS achievement_id,
+    '' AS description,
+    updated_at AS created_at
+FROM daily_attendances
+WHERE points <> 0
+UNION ALL
+SELECT user_id,
+    source,
+    amount,
+    NULL::DATE AS attendance_date,
+    achievement_id,
+    description,
+    created_at
+FROM point_transactions;
+-- コメント
+COMMENT ON TABLE point_transactions IS 'ポイント取引（出席以外のポイントの増減）';
+COMMENT ON COLUMN point_transactions.source IS '種別（achievement: 称号の報酬）';
+COMMENT ON COLUMN point_transactions.amount IS '増減するポイント';
+COMMENT ON VIEW point_ledger IS 'ポイント台帳（出席ポイント + ポイント取引）';
+COMMENT ON COLUMN daily_attendances.points IS '獲得ポイント（point_rules 設定に基づく）';
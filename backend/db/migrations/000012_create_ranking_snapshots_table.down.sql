@@ -0,0 +1,4 @@
+-- ランキングのスナップショットテーブルの削除
+DROP INDEX IF EXISTS idx_ranking_snapshots_user;
+DROP INDEX IF EXISTS idx_ranking_snapshots_period;
+DROP TABLE IF EXISTS ranking_snapshots;
@@ -0,0 +1,26 @@
+-- ランキングのスナップショットテーブルの作成（締めた週・月のランキングを保存する）
+CREATE TABLE IF NOT EXISTS ranking_snapshots (
+    id SERIAL PRIMARY KEY,
+    period VARCHAR(20) NOT NULL,
+    metric VARCHAR(20) NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    value INTEGER NOT NULL,
+    total_duration INTEGER NOT NULL DEFAULT 0,
+    attendance_days INTEGER NOT NULL DEFAULT 0,
+    total_points INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (period, metric, period_start, user_id)
+);
+-- インデックスの作成
+CREATE INDEX idx_ranking_snapshots_period ON ranking_snapshots(period, period_start);
+CREATE INDEX idx_ranking_snapshots_user ON ranking_snapshots(user_id, period, metric, period_start);
+-- コメント
+COMMENT ON TABLE ranking_snapshots IS 'ランキングのスナップショット（締めた週・月の順位）';
+COMMENT ON COLUMN ranking_snapshots.period IS '期間の種類（weekly, monthly）';
+COMMENT ON COLUMN ranking_snapshots.metric IS '順位付けの指標（minutes, days, current_streak, longest_streak, points）';
+COMMENT ON COLUMN ranking_snapshots.period_start IS '期間の初日';
+COMMENT ON COLUMN ranking_snapshots.period_end IS '期間の最終日';
+COMMENT ON COLUMN ranking_snapshots.value IS '指標の値';
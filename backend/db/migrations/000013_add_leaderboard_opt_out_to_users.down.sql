@@ -0,0 +1,2 @@
+-- ランキングへの参加の有無の削除
+ALTER TABLE users DROP COLUMN IF EXISTS is_leaderboard_public;
@@ -0,0 +1,5 @@
+-- ランキングへの参加の有無の追加
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS is_leaderboard_public BOOLEAN NOT NULL DEFAULT TRUE;
+-- コメント
+COMMENT ON COLUMN users.is_leaderboard_public IS 'ランキングに参加するか（在室状態の公開 is_presence_public とは独立）';
@@ -0,0 +1,6 @@
+-- 称号の手動付与・取り消しの履歴テーブルの削除
+DROP INDEX IF EXISTS idx_achievement_award_logs_user;
+DROP TABLE IF EXISTS achievement_award_logs;
+-- 手動付与した称号の付与者とメモの削除
+ALTER TABLE user_achievements DROP COLUMN IF EXISTS note;
+ALTER TABLE user_achievements DROP COLUMN IF EXISTS awarded_by;
@@ -0,0 +1,21 @@
+-- 手動付与した称号の付与者とメモの追加
+ALTER TABLE user_achievements
+ADD COLUMN IF NOT EXISTS awarded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+-- 称号の手動付与・取り消しの履歴テーブルの作成
+CREATE TABLE IF NOT EXISTS achievement_award_logs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    achievement_id INTEGER NOT NULL REFERENCES achievements(id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL,
+    note TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- インデックスの作成
+CREATE INDEX idx_achievement_award_logs_user ON achievement_award_logs(user_id, created_at);
+-- コメント
+COMMENT ON COLUMN user_achievements.awarded_by IS '手動で付与した教員・管理者（自動で解除した場合は NULL）';
+COMMENT ON COLUMN user_achievements.note IS '手動で付与した理由';
+COMMENT ON TABLE achievement_award_logs IS '称号の手動付与・取り消しの履歴';
+COMMENT ON COLUMN achievement_award_logs.action IS '操作（award, revoke）';
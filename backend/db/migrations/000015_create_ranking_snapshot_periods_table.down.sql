@@ -0,0 +1,2 @@
+-- ランキングを保存した期間のテーブルの削除
+DROP TABLE IF EXISTS ranking_snapshot_periods;
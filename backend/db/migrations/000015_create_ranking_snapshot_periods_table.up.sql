@@ -0,0 +1,20 @@
+-- ランキングを保存した期間のテーブルの作成（順位の無い期間も保存済みとして記録する）
+CREATE TABLE IF NOT EXISTS ranking_snapshot_periods (
+    id SERIAL PRIMARY KEY,
+    period VARCHAR(20) NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (period, period_start)
+);
+-- 保存済みのスナップショットの期間を移す
+INSERT INTO ranking_snapshot_periods (period, period_start, period_end, created_at)
+SELECT period, period_start, MAX(period_end), MIN(created_at)
+FROM ranking_snapshots
+GROUP BY period, period_start
+ON CONFLICT DO NOTHING;
+-- コメント
+COMMENT ON TABLE ranking_snapshot_periods IS 'ランキングのスナップショットを保存した期間';
+COMMENT ON COLUMN ranking_snapshot_periods.period IS '期間の種類（weekly, monthly）';
+COMMENT ON COLUMN ranking_snapshot_periods.period_start IS '期間の初日';
+COMMENT ON COLUMN ranking_snapshot_periods.period_end IS '期間の最終日';
@@ -174,4 +174,15 @@ VALUES -- 早起き系
         '{"count": 5}',
         10,
         101
+    ),
+    -- 教員・管理者が手動で付与する
+    (
+        'conference_presentation',
+        '学会デビュー',
+        '学会で発表した',
+        'special',
+        'manual',
+        '{}',
+        100,
+        102
     );
\ No newline at end of file
@@ -10,10 +10,30 @@ VALUES (
         '["2025-01-01", "2025-01-13", "2025-02-11", "2025-02-23", "2025-03-20", "2025-05-02", "2025-05-03", "2025-05-04", "2025-05-05", "2025-05-06", "2025-08-11", "2025-08-12", "2025-09-15", "2025-09-23", "2025-11-23", "2025-11-24", "2025-12-25", "2025-12-26", "2026-01-01", "2026-01-12", "2026-02-11", "2026-02-23", "2026-03-20"]',
         '休日リスト（2025年度：大学学年暦に基づく）'
     ),
+    (
+        'holiday_rules',
+        '{"streak_skips_holidays": true, "streak_skips_weekends": true, "ranking_excludes_holidays": false}',
+        '休日の扱い（streak_skips_holidays / streak_skips_weekends: 休日・土日に来なくても連続出席が途切れない、ranking_excludes_holidays: ランキングで休日の滞在時間を数えない）'
+    ),
+    (
+        'point_rules',
+        '{"daily_points": 1, "min_minutes": 30, "holiday_multiplier": 2}',
+        '出席ポイントの付与ルール（daily_points: 1日の出席ポイント、min_minutes: 必要な滞在時間（分）、holiday_multiplier: 休日の倍率）'
+    ),
+    (
+        'academic_terms',
+        '{"spring": {"start": "04-01", "end": "09-30"}, "fall": {"start": "10-01", "end": "03-31"}}',
+        '学期の定義（年度内の開始日と終了日を MM-DD で指定、1〜3月は翌年。ランキングの ?type=term&term=spring で使う）'
+    ),
+    (
+        'achievement_announcements',
+        'false',
+        '出席による称号の解除を研究室全体に知らせるか（true の場合、出席データを公開しているユーザーの解除を全員に配信する。教員・管理者による手動付与は常に知らせる）'
+    ),
     (
         'allowed_ip_range',
-        '{"ips": ["133.38.201.125"]}',
-        '許可されたIP範囲'
+        '{"ips": ["133.38.201.125"], "deny": []}',
+        '許可されたIP範囲（ips: 許可する単一IPまたはCIDR、deny: 拒否するIPまたはCIDR。IPv6可）'
     ),
     (
         'gps_location',
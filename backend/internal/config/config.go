@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config システム全体の設定
@@ -12,12 +15,20 @@ type Config struct {
 	LDAP     LDAPConfig
 	JWT      JWTConfig
 	Location LocationConfig
+	Lab      LabConfig
 }
 
 // ServerConfig サーバー設定
 type ServerConfig struct {
 	Port string
 	Env  string
+	// TrustedProxies クライアントIPの転送ヘッダーを信頼するプロキシ（IPまたはCIDR）
+	// 空の場合はどのプロキシも信頼せず、接続元アドレスをそのままクライアントIPとする（production では起動しない）
+	TrustedProxies []string
+	// ForwardedHeader 信頼するプロキシが付与するクライアントIPのヘッダー名
+	ForwardedHeader string
+	// UploadDir アップロードされたファイル（称号のアイコン）の保存先
+	UploadDir string
 }
 
 // DatabaseConfig データベース設定
@@ -55,12 +66,30 @@ type LocationConfig struct {
 	Radius    float64
 }
 
+// LabConfig 研究室の運用設定
+type LabConfig struct {
+	// TimeZone 研究室のタイムゾーン（IANA名）。日付の区切り・ランキング期間・称号の時刻条件・DBセッションの基準になる
+	TimeZone string
+}
+
+// Location TimeZone を読み込む
+func (c LabConfig) Location() (*time.Location, error) {
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("LAB_TIMEZONE %q: %w", c.TimeZone, err)
+	}
+	return loc, nil
+}
+
 // Load 環境変数から設定を読み込む
 func Load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port:            getEnv("PORT", "8080"),
+			Env:             getEnv("ENV", "development"),
+			TrustedProxies:  getEnvAsSlice("TRUSTED_PROXIES", nil),
+			ForwardedHeader: getEnv("FORWARDED_HEADER", "X-Forwarded-For"),
+			UploadDir:       getEnv("UPLOAD_DIR", "uploads"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -89,6 +118,9 @@ func Load() *Config {
 			Longitude: getEnvAsFloat("LAB_LONGITUDE", 139.7671),
 			Radius:    getEnvAsFloat("LAB_RADIUS_METERS", 100.0),
 		},
+		Lab: LabConfig{
+			TimeZone: getEnv("LAB_TIMEZONE", "Asia/Tokyo"),
+		},
 	}
 }
 
@@ -126,3 +158,18 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+// getEnvAsSlice 環境変数をカンマ区切りのリストとして取得
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	var values []string
+	for _, v := range strings.Split(valueStr, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -13,14 +13,16 @@ import (
 
 // NewDatabase データベース接続を作成
 func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
+	// セッションのタイムゾーンを研究室に合わせる（NOW() や日付への変換の基準になる）
 	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=%s",
 		cfg.Database.Host,
 		cfg.Database.Port,
 		cfg.Database.User,
 		cfg.Database.Password,
 		cfg.Database.DBName,
 		cfg.Database.SSLMode,
+		cfg.Lab.TimeZone,
 	)
 
 	// ログレベルの設定
@@ -50,6 +52,11 @@ func AutoMigrate(db *gorm.DB) error {
 		&domain.Achievement{},
 		&domain.UserAchievement{},
 		&domain.Setting{},
+		&domain.AttendanceAuditLog{},
+		&domain.AttendanceCorrectionRequest{},
+		&domain.PointTransaction{},
+		&domain.RankingSnapshot{},
+		&domain.RankingSnapshotPeriod{},
 	)
 }
 
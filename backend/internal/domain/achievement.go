@@ -51,6 +51,9 @@ type UserAchievement struct {
 	UserID        uint      `json:"user_id" gorm:"not null;index"`
 	AchievementID uint      `json:"achievement_id" gorm:"not null;index"`
 	AchievedAt    time.Time `json:"achieved_at" gorm:"not null"`
+	// AwardedBy 手動で付与した教員・管理者（自動で解除した場合は nil）
+	AwardedBy *uint  `json:"awarded_by,omitempty"`
+	Note      string `json:"note,omitempty" gorm:"not null;default:''"`
 
 	// リレーション
 	User        User        `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -61,3 +64,29 @@ type UserAchievement struct {
 func (UserAchievement) TableName() string {
 	return "user_achievements"
 }
+
+// 称号の手動付与の操作（achievement_award_logs.action）
+const (
+	AchievementAwardActionAward  = "award"
+	AchievementAwardActionRevoke = "revoke"
+)
+
+// AchievementAwardLog 称号の手動付与・取り消しの履歴
+type AchievementAwardLog struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        uint      `json:"user_id" gorm:"not null;index"`
+	AchievementID uint      `json:"achievement_id" gorm:"not null"`
+	ActorID       *uint     `json:"actor_id"`
+	Action        string    `json:"action" gorm:"not null"` // award, revoke
+	Note          string    `json:"note" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// リレーション
+	Achievement Achievement `json:"achievement,omitempty" gorm:"foreignKey:AchievementID"`
+	Actor       *User       `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+}
+
+// TableName テーブル名を指定
+func (AchievementAwardLog) TableName() string {
+	return "achievement_award_logs"
+}
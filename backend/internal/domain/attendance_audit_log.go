@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// 出席記録の修正操作
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AttendanceAuditLog 出席記録の修正履歴
+type AttendanceAuditLog struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CheckInLogID uint      `json:"check_in_log_id" gorm:"not null;index"`
+	UserID       uint      `json:"user_id" gorm:"not null;index"`
+	ActorID      *uint     `json:"actor_id"`
+	Action       string    `json:"action" gorm:"not null"` // create, update, delete
+	Reason       string    `json:"reason" gorm:"not null"`
+	BeforeValue  JSONB     `json:"before_value" gorm:"type:jsonb"`
+	AfterValue   JSONB     `json:"after_value" gorm:"type:jsonb"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// リレーション
+	Actor *User `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+}
+
+// TableName テーブル名を指定
+func (AttendanceAuditLog) TableName() string {
+	return "attendance_audit_logs"
+}
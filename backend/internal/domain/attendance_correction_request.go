@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// 訂正申請の種別
+const (
+	CorrectionTypeMissing = "missing" // 記録漏れ（チェックインし忘れた）
+	CorrectionTypeModify  = "modify"  // 既存の記録の誤り
+)
+
+// 訂正申請の状態
+const (
+	CorrectionStatusPending  = "pending"
+	CorrectionStatusApproved = "approved"
+	CorrectionStatusRejected = "rejected"
+)
+
+// AttendanceCorrectionRequest 出席記録の訂正申請
+type AttendanceCorrectionRequest struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint       `json:"user_id" gorm:"not null;index"`
+	CheckInLogID  *uint      `json:"check_in_log_id"`
+	RequestType   string     `json:"request_type" gorm:"not null"` // missing, modify
+	CheckInAt     time.Time  `json:"check_in_at" gorm:"not null"`
+	CheckOutAt    time.Time  `json:"check_out_at" gorm:"not null"`
+	Reason        string     `json:"reason" gorm:"not null"`
+	Status        string     `json:"status" gorm:"not null;default:'pending';index"` // pending, approved, rejected
+	ReviewerID    *uint      `json:"reviewer_id"`
+	ReviewComment string     `json:"review_comment"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// リレーション
+	User     User  `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Reviewer *User `json:"reviewer,omitempty" gorm:"foreignKey:ReviewerID"`
+}
+
+// TableName テーブル名を指定
+func (AttendanceCorrectionRequest) TableName() string {
+	return "attendance_correction_requests"
+}
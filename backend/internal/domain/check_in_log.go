@@ -13,6 +13,7 @@ type CheckInLog struct {
 	WiFiSSID        string     `json:"wifi_ssid" gorm:"column:wifi_ssid"`
 	GPSLatitude     *float64   `json:"gps_latitude"`
 	GPSLongitude    *float64   `json:"gps_longitude"`
+	IsAutoCheckout  bool       `json:"is_auto_checkout" gorm:"not null;default:false"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
 
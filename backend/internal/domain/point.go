@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// ポイントの種別
+const (
+	PointSourceAttendance  = "attendance"  // 日次の出席ポイント（daily_attendances.points）
+	PointSourceAchievement = "achievement" // 称号の報酬
+)
+
+// PointTransaction 出席以外のポイントの増減
+type PointTransaction struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	UserID        uint      `json:"user_id" gorm:"not null;index"`
+	Source        string    `json:"source" gorm:"not null"`
+	Amount        int       `json:"amount" gorm:"not null"`
+	AchievementID *uint     `json:"achievement_id"`
+	Description   string    `json:"description" gorm:"not null;default:''"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName テーブル名を指定
+func (PointTransaction) TableName() string {
+	return "point_transactions"
+}
+
+// PointLedgerEntry ポイント台帳（point_ledger ビュー）の1行
+type PointLedgerEntry struct {
+	UserID         uint       `json:"user_id"`
+	Source         string     `json:"source"`
+	Amount         int        `json:"amount"`
+	AttendanceDate *time.Time `json:"attendance_date,omitempty"`
+	AchievementID  *uint      `json:"achievement_id,omitempty"`
+	Description    string     `json:"description"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -1,8 +1,62 @@
 package domain
 
+import "time"
+
 type UserRanking struct {
-	UserID        uint   `json:"user_id"`
-	DisplayName   string `json:"display_name"`
-	Username      string `json:"username"`
-	TotalDuration int    `json:"total_duration"` // 分単位
+	UserID            uint   `json:"user_id"`
+	DisplayName       string `json:"display_name"`
+	Username          string `json:"username"`
+	TotalDuration     int    `json:"total_duration"` // 分単位
+	AttendanceDays    int    `json:"attendance_days"`
+	TotalPoints       int    `json:"total_points"`
+	IsPrivate         bool   `json:"is_private"` // 閲覧者に公開されていないユーザー（表示名を伏せて返す）
+	ProfileVisibility string `json:"-"`
+
+	// Metric 順位付けに使った指標（minutes, days, current_streak, longest_streak, points）
+	Metric string `json:"metric"`
+	// Value 指標の値
+	Value int `json:"value"`
+	// Rank 順位（同じ値は同順位で、次の順位はその人数分飛ぶ）
+	Rank int `json:"rank"`
+	// PreviousRank, RankDelta 直前の期間の順位と、そこからの変化（上がった場合は正）。
+	// 直前の期間と比べていない場合や、直前の期間に順位が無い場合は省略する
+	PreviousRank *int `json:"previous_rank,omitempty"`
+	RankDelta    *int `json:"rank_delta,omitempty"`
+}
+
+// RankingSnapshot 締めた期間のランキングの1ユーザー分
+type RankingSnapshot struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	Period string `json:"period" gorm:"not null;uniqueIndex:idx_ranking_snapshots_entry"` // weekly, monthly
+	Metric string `json:"metric" gorm:"not null;uniqueIndex:idx_ranking_snapshots_entry"`
+	// PeriodStart, PeriodEnd 期間の初日と最終日
+	PeriodStart    time.Time `json:"period_start" gorm:"not null;type:date;uniqueIndex:idx_ranking_snapshots_entry"`
+	PeriodEnd      time.Time `json:"period_end" gorm:"not null;type:date"`
+	UserID         uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_ranking_snapshots_entry"`
+	Rank           int       `json:"rank" gorm:"not null"`
+	Value          int       `json:"value" gorm:"not null"`
+	TotalDuration  int       `json:"total_duration" gorm:"not null;default:0"`
+	AttendanceDays int       `json:"attendance_days" gorm:"not null;default:0"`
+	TotalPoints    int       `json:"total_points" gorm:"not null;default:0"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName テーブル名を指定
+func (RankingSnapshot) TableName() string {
+	return "ranking_snapshots"
+}
+
+// RankingSnapshotPeriod ランキングを保存した期間（順位の無い期間も記録する）
+type RankingSnapshotPeriod struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	Period string `json:"period" gorm:"not null;uniqueIndex:idx_ranking_snapshot_periods_start"` // weekly, monthly
+	// PeriodStart, PeriodEnd 期間の初日と最終日
+	PeriodStart time.Time `json:"period_start" gorm:"not null;type:date;uniqueIndex:idx_ranking_snapshot_periods_start"`
+	PeriodEnd   time.Time `json:"period_end" gorm:"not null;type:date"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName テーブル名を指定
+func (RankingSnapshotPeriod) TableName() string {
+	return "ranking_snapshot_periods"
 }
@@ -2,21 +2,63 @@ package domain
 
 import "time"
 
+// 出席データ（ヒートマップ・履歴・称号・ランキング）の公開範囲
+const (
+	VisibilityPublic  = "public"  // ログインできる全員（卒業生など在籍していないユーザーも含む）
+	VisibilityMembers = "members" // 在籍中（is_active）の研究室メンバーのみ
+	VisibilityPrivate = "private" // 本人のみ
+)
+
 // User ユーザー情報
 type User struct {
-	ID               uint       `json:"id" gorm:"primaryKey"`
-	Username         string     `json:"username" gorm:"uniqueIndex;not null"`
-	DisplayName      string     `json:"display_name" gorm:"not null"`
-	Email            string     `json:"email"`
-	Role             string     `json:"role" gorm:"not null;default:'student'"` // student, teacher, admin
-	IsPresencePublic bool       `json:"is_presence_public" gorm:"not null;default:true"`
-	CreatedAt        time.Time  `json:"created_at"`
-	UpdatedAt        time.Time  `json:"updated_at"`
-	LastLoginAt      *time.Time `json:"last_login_at"`
-	IsActive         bool       `json:"is_active" gorm:"not null;default:true"`
+	ID               uint   `json:"id" gorm:"primaryKey"`
+	Username         string `json:"username" gorm:"uniqueIndex;not null"`
+	DisplayName      string `json:"display_name" gorm:"not null"`
+	Email            string `json:"email"`
+	Role             string `json:"role" gorm:"not null;default:'student'"` // student, teacher, admin
+	IsPresencePublic bool   `json:"is_presence_public" gorm:"not null;default:true"`
+	// IsLeaderboardPublic ランキングに参加するか（在室状態の公開とは別に設定する）
+	IsLeaderboardPublic bool       `json:"is_leaderboard_public" gorm:"not null;default:true"`
+	ProfileVisibility   string     `json:"profile_visibility" gorm:"not null;default:'members'"` // public, members, private
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	LastLoginAt         *time.Time `json:"last_login_at"`
+	IsActive            bool       `json:"is_active" gorm:"not null;default:true"`
 }
 
 // TableName テーブル名を指定
 func (User) TableName() string {
 	return "users"
 }
+
+// IsStaff 教員・管理者か（公開範囲に関係なく全員の出席データを閲覧できる）
+func (u *User) IsStaff() bool {
+	return u.Role == "teacher" || u.Role == "admin"
+}
+
+// IsVisibleTo viewer が u の出席データを閲覧できるか
+func (u *User) IsVisibleTo(viewer *User) bool {
+	if viewer == nil {
+		return u.ProfileVisibility == VisibilityPublic
+	}
+	if viewer.ID == u.ID || viewer.IsStaff() {
+		return true
+	}
+	switch u.ProfileVisibility {
+	case VisibilityPublic:
+		return true
+	case VisibilityMembers:
+		return viewer.IsActive
+	default:
+		return false
+	}
+}
+
+// IsValidVisibility 公開範囲の値が正しいか
+func IsValidVisibility(v string) bool {
+	switch v {
+	case VisibilityPublic, VisibilityMembers, VisibilityPrivate:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "testing"
+
+func TestUser_IsVisibleTo(t *testing.T) {
+	member := &User{ID: 2, Role: "student", IsActive: true}
+	alumni := &User{ID: 3, Role: "student", IsActive: false}
+	teacher := &User{ID: 4, Role: "teacher", IsActive: true}
+	admin := &User{ID: 5, Role: "admin", IsActive: true}
+
+	tests := []struct {
+		visibility string
+		viewer     *User
+		want       bool
+	}{
+		{VisibilityPublic, nil, true},
+		{VisibilityPublic, member, true},
+		{VisibilityPublic, alumni, true},
+		{VisibilityMembers, nil, false},
+		{VisibilityMembers, member, true},
+		{VisibilityMembers, alumni, false},
+		{VisibilityMembers, teacher, true},
+		{VisibilityPrivate, nil, false},
+		{VisibilityPrivate, member, false},
+		{VisibilityPrivate, alumni, false},
+		{VisibilityPrivate, teacher, true},
+		{VisibilityPrivate, admin, true},
+		// 不明な値は非公開として扱う
+		{"", member, false},
+	}
+	for _, tt := range tests {
+		target := &User{ID: 1, Role: "student", IsActive: true, ProfileVisibility: tt.visibility}
+		viewerName := "anonymous"
+		if tt.viewer != nil {
+			viewerName = tt.viewer.Role
+			if !tt.viewer.IsActive {
+				viewerName += " (inactive)"
+			}
+		}
+		if got := target.IsVisibleTo(tt.viewer); got != tt.want {
+			t.Errorf("%q visible to %s = %v, want %v", tt.visibility, viewerName, got, tt.want)
+		}
+	}
+}
+
+func TestUser_IsVisibleToSelf(t *testing.T) {
+	for _, visibility := range []string{VisibilityPublic, VisibilityMembers, VisibilityPrivate} {
+		// 在籍していない本人も自分のデータは見られる
+		u := &User{ID: 1, Role: "student", IsActive: false, ProfileVisibility: visibility}
+		if !u.IsVisibleTo(u) {
+			t.Errorf("%q is not visible to the user themselves", visibility)
+		}
+	}
+}
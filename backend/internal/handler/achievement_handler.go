@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -9,11 +10,12 @@ import (
 )
 
 type AchievementHandler struct {
-	service service.AchievementService
+	service  service.AchievementService
+	rankings service.RankingInvalidator
 }
 
-func NewAchievementHandler(service service.AchievementService) *AchievementHandler {
-	return &AchievementHandler{service: service}
+func NewAchievementHandler(service service.AchievementService, rankings service.RankingInvalidator) *AchievementHandler {
+	return &AchievementHandler{service: service, rankings: rankings}
 }
 
 func (h *AchievementHandler) GetAchievements(c *gin.Context) {
@@ -25,15 +27,11 @@ func (h *AchievementHandler) GetAchievements(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"achievements": achievements})
 }
 
+// GetUserAchievements 対象ユーザーと閲覧可否は UserVisibilityMiddleware で解決済み
 func (h *AchievementHandler) GetUserAchievements(c *gin.Context) {
-	userIDStr := c.Param("id")
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
-	}
+	userID := c.GetUint("target_user_id")
 
-	achievements, err := h.service.GetUserAchievements(c.Request.Context(), uint(userID))
+	achievements, err := h.service.GetUserAchievements(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -55,3 +53,153 @@ func (h *AchievementHandler) GetMyAchievements(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"user_achievements": achievements})
 }
+
+// GetMyProgress 有効な全ての称号について、ログインユーザーの進捗を返す
+func (h *AchievementHandler) GetMyProgress(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	progress, err := h.service.GetProgress(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"progress": progress})
+}
+
+// Recompute 出席履歴から称号を判定し直し、付与漏れの称号を付与する。
+// ?user_id= で対象を1人に絞る。?apply=true を付けない場合は付与される称号の確認のみ行う
+func (h *AchievementHandler) Recompute(c *gin.Context) {
+	var userID uint
+	if v := c.Query("user_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		userID = uint(id)
+	}
+
+	apply := c.Query("apply") == "true"
+	result, err := h.service.Recompute(c.Request.Context(), userID, apply)
+	if result != nil && apply {
+		// 途中で失敗した場合も、付与済みの分の報酬ポイントをランキングに反映する
+		if ids := result.UserIDs(); len(ids) > 0 {
+			h.rankings.Invalidate(ids...)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+type awardAchievementRequest struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Note   string `json:"note"`
+}
+
+type revokeAchievementRequest struct {
+	Note string `json:"note"`
+}
+
+// Award 手動付与の称号をユーザーに付与する
+func (h *AchievementHandler) Award(c *gin.Context) {
+	achievementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid achievement ID"})
+		return
+	}
+
+	var req awardAchievementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ua, err := h.service.Award(c.Request.Context(), c.GetUint("user_id"), uint(achievementID), req.UserID, req.Note)
+	if err != nil {
+		respondAwardError(c, err)
+		return
+	}
+	// 報酬のポイントをランキングに反映する
+	h.rankings.Invalidate(req.UserID)
+	c.JSON(http.StatusCreated, gin.H{"user_achievement": ua})
+}
+
+// Revoke 手動付与した称号を取り消す（報酬のポイントも取り消す）
+func (h *AchievementHandler) Revoke(c *gin.Context) {
+	achievementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid achievement ID"})
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req revokeAchievementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), c.GetUint("user_id"), uint(achievementID), uint(userID), req.Note); err != nil {
+		respondAwardError(c, err)
+		return
+	}
+	h.rankings.Invalidate(uint(userID))
+	c.JSON(http.StatusOK, gin.H{"message": "achievement revoked"})
+}
+
+// GetAwardLogs 手動付与・取り消しの履歴を取得（?user_id= で絞り込み）
+func (h *AchievementHandler) GetAwardLogs(c *gin.Context) {
+	var userID uint64
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		var err error
+		userID, err = strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+	}
+
+	logs, err := h.service.GetAwardLogs(c.Request.Context(), uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"award_logs": logs})
+}
+
+// respondAwardError 称号の手動付与エラーをステータスコードに変換する
+func respondAwardError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAchievementNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "achievement not found"})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, service.ErrAwardNoteRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note is required"})
+	case errors.Is(err, service.ErrNotManualAchievement):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAchievementInactive):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "achievement is inactive"})
+	case errors.Is(err, service.ErrAchievementAwarded):
+		c.JSON(http.StatusConflict, gin.H{"error": "achievement already unlocked"})
+	case errors.Is(err, service.ErrAchievementNotAwarded):
+		c.JSON(http.StatusNotFound, gin.H{"error": "achievement not unlocked"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
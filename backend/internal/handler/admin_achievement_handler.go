@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kasa021/watabe-lab-app/internal/service"
+)
+
+// AdminAchievementHandler 管理者・教員向けの称号マスタ管理API
+type AdminAchievementHandler struct {
+	service service.AchievementAdminService
+}
+
+func NewAdminAchievementHandler(service service.AchievementAdminService) *AdminAchievementHandler {
+	return &AdminAchievementHandler{service: service}
+}
+
+type reorderAchievementsRequest struct {
+	IDs []uint `json:"ids" binding:"required"`
+}
+
+// ListAchievements 無効な称号も含めて表示順に取得
+func (h *AdminAchievementHandler) ListAchievements(c *gin.Context) {
+	achievements, err := h.service.ListAchievements(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"achievements": achievements})
+}
+
+// CreateAchievement 称号を追加
+func (h *AdminAchievementHandler) CreateAchievement(c *gin.Context) {
+	var req service.AchievementInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	achievement, err := h.service.CreateAchievement(c.Request.Context(), &req)
+	if err != nil {
+		respondAchievementError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"achievement": achievement})
+}
+
+// UpdateAchievement 称号を更新（is_active: false で無効化）。
+// description・points_reward・icon_url・is_active・display_order は省略すると変更しない
+func (h *AdminAchievementHandler) UpdateAchievement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid achievement ID"})
+		return
+	}
+
+	var req service.AchievementInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	achievement, err := h.service.UpdateAchievement(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		respondAchievementError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"achievement": achievement})
+}
+
+// DeleteAchievement 称号を削除。獲得したユーザーがいる場合は ?force=true が必要
+func (h *AdminAchievementHandler) DeleteAchievement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid achievement ID"})
+		return
+	}
+
+	force := c.Query("force") == "true"
+	if err := h.service.DeleteAchievement(c.Request.Context(), uint(id), force); err != nil {
+		respondAchievementError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "achievement deleted"})
+}
+
+// ReorderAchievements 表示順を並べ替える（ids に全ての称号の ID を表示順に指定）
+func (h *AdminAchievementHandler) ReorderAchievements(c *gin.Context) {
+	var req reorderAchievementsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	achievements, err := h.service.Reorder(c.Request.Context(), req.IDs)
+	if err != nil {
+		respondAchievementError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"achievements": achievements})
+}
+
+// UploadIcon アイコン画像（multipart の icon）をアップロード
+func (h *AdminAchievementHandler) UploadIcon(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid achievement ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("icon")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "icon is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	achievement, err := h.service.SetIcon(c.Request.Context(), uint(id), file)
+	if err != nil {
+		respondAchievementError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"achievement": achievement})
+}
+
+// respondAchievementError 称号マスタの管理エラーをステータスコードに変換する
+func respondAchievementError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAchievementNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "achievement not found"})
+	case errors.Is(err, service.ErrInvalidAchievement),
+		errors.Is(err, service.ErrUnknownConditionType),
+		errors.Is(err, service.ErrInvalidConditionValue),
+		errors.Is(err, service.ErrInvalidIcon):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAchievementCodeTaken),
+		errors.Is(err, service.ErrAchievementUnlocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
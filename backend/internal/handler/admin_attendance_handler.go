@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kasa021/watabe-lab-app/internal/service"
+)
+
+// AdminAttendanceHandler 管理者・教員向けの出席記録修正API
+type AdminAttendanceHandler struct {
+	service service.AttendanceAdminService
+}
+
+func NewAdminAttendanceHandler(service service.AttendanceAdminService) *AdminAttendanceHandler {
+	return &AdminAttendanceHandler{service: service}
+}
+
+type deleteSessionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// CreateSession 出席記録を追加（チェックイン忘れの補填）
+func (h *AdminAttendanceHandler) CreateSession(c *gin.Context) {
+	var req service.SessionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log, err := h.service.CreateSession(c.Request.Context(), c.GetUint("user_id"), &req)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"session": log})
+}
+
+// UpdateSession 出席記録を修正（チェックアウト忘れ等）
+func (h *AdminAttendanceHandler) UpdateSession(c *gin.Context) {
+	logID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req service.SessionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log, err := h.service.UpdateSession(c.Request.Context(), c.GetUint("user_id"), uint(logID), &req)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"session": log})
+}
+
+// DeleteSession 出席記録を削除
+func (h *AdminAttendanceHandler) DeleteSession(c *gin.Context) {
+	logID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var req deleteSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DeleteSession(c.Request.Context(), c.GetUint("user_id"), uint(logID), req.Reason); err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "session deleted"})
+}
+
+// GetAuditLogs 修正履歴を取得（?user_id= で絞り込み）
+func (h *AdminAttendanceHandler) GetAuditLogs(c *gin.Context) {
+	var userID uint64
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		var err error
+		userID, err = strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+	}
+
+	audits, err := h.service.GetAuditLogs(c.Request.Context(), uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"audit_logs": audits})
+}
+
+// respondSessionError 出席記録の修正エラーをステータスコードに変換する
+func respondSessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, service.ErrReasonRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+	case errors.Is(err, service.ErrInvalidSession):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSessionOverlap):
+		c.JSON(http.StatusConflict, gin.H{"error": "session overlaps another session"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/service"
+)
+
+// CorrectionRequestHandler 出席記録の訂正申請API
+type CorrectionRequestHandler struct {
+	service service.CorrectionRequestService
+}
+
+func NewCorrectionRequestHandler(service service.CorrectionRequestService) *CorrectionRequestHandler {
+	return &CorrectionRequestHandler{service: service}
+}
+
+type reviewCorrectionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// Submit 訂正申請を提出
+func (h *CorrectionRequestHandler) Submit(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req service.CorrectionRequestInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.service.Submit(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		respondCorrectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"correction_request": created})
+}
+
+// GetMyRequests 自分の訂正申請一覧
+func (h *CorrectionRequestHandler) GetMyRequests(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	reqs, err := h.service.GetMyRequests(c.Request.Context(), userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"correction_requests": reqs})
+}
+
+// GetRequests 訂正申請一覧（管理者・教員用、?status= 省略時は承認待ち）
+func (h *CorrectionRequestHandler) GetRequests(c *gin.Context) {
+	status := c.DefaultQuery("status", domain.CorrectionStatusPending)
+
+	reqs, err := h.service.GetRequestsByStatus(c.Request.Context(), status)
+	if err != nil {
+		respondCorrectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"correction_requests": reqs})
+}
+
+// Approve 訂正申請を承認
+func (h *CorrectionRequestHandler) Approve(c *gin.Context) {
+	h.review(c, h.service.Approve)
+}
+
+// Reject 訂正申請を却下
+func (h *CorrectionRequestHandler) Reject(c *gin.Context) {
+	h.review(c, h.service.Reject)
+}
+
+func (h *CorrectionRequestHandler) review(c *gin.Context, decide func(ctx context.Context, reviewerID uint, requestID uint, comment string) (*domain.AttendanceCorrectionRequest, error)) {
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+
+	var req reviewCorrectionRequest
+	// コメントは任意なのでボディが空でもよい
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	decided, err := decide(c.Request.Context(), c.GetUint("user_id"), uint(requestID), req.Comment)
+	if err != nil {
+		respondCorrectionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"correction_request": decided})
+}
+
+// respondCorrectionError 訂正申請のエラーをステータスコードに変換する
+func respondCorrectionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCorrectionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "correction request not found"})
+	case errors.Is(err, service.ErrCorrectionNotPending),
+		errors.Is(err, service.ErrCorrectionAlreadyPending),
+		errors.Is(err, service.ErrCorrectionSessionDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCannotReviewOwnRequest):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCorrectionStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		respondSessionError(c, err)
+	}
+}
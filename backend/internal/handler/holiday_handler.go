@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kasa021/watabe-lab-app/internal/service"
+)
+
+// HolidayHandler 休日カレンダーの管理API（年度単位）
+type HolidayHandler struct {
+	service service.HolidayService
+}
+
+func NewHolidayHandler(service service.HolidayService) *HolidayHandler {
+	return &HolidayHandler{service: service}
+}
+
+type setHolidaysRequest struct {
+	Dates []string `json:"dates"`
+}
+
+// GetHolidays 年度の休日一覧を取得
+func (h *HolidayHandler) GetHolidays(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid academic year"})
+		return
+	}
+
+	dates, err := h.service.GetHolidays(c.Request.Context(), year)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"academic_year": year, "holidays": dates})
+}
+
+// SetHolidays 年度の休日一覧を置き換える
+func (h *HolidayHandler) SetHolidays(c *gin.Context) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid academic year"})
+		return
+	}
+
+	var req setHolidaysRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dates, err := h.service.SetHolidays(c.Request.Context(), c.GetUint("user_id"), year, req.Dates)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidHoliday) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"academic_year": year, "holidays": dates})
+}
+
+// ImportICal iCalendar ファイル（multipart の file）の終日イベントを休日に取り込む。
+// ?apply=true を付けない場合は追加される日の確認のみ行う
+func (h *HolidayHandler) ImportICal(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	apply := c.Query("apply") == "true"
+	result, err := h.service.ImportICal(c.Request.Context(), c.GetUint("user_id"), file, apply)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidICal) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kasa021/watabe-lab-app/internal/service"
+)
+
+// PointHandler ポイントAPI
+type PointHandler struct {
+	service service.PointService
+}
+
+func NewPointHandler(service service.PointService) *PointHandler {
+	return &PointHandler{service: service}
+}
+
+// GetBalance ユーザーのポイント残高と台帳を取得（/users/:id/points）
+func (h *PointHandler) GetBalance(c *gin.Context) {
+	balance, err := h.service.GetBalance(c.Request.Context(), c.GetUint("target_user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
@@ -1,43 +1,135 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
 	"github.com/kasa021/watabe-lab-app/internal/service"
 )
 
+// HeaderRankingCacheAge ランキングの集計に使ったキャッシュの経過秒数を返すヘッダー
+const HeaderRankingCacheAge = "X-Ranking-Cache-Age"
+
 type RankingHandler struct {
-	service service.RankingService
+	service         service.RankingService
+	snapshotService service.RankingSnapshotService
+	userRepo        repository.UserRepository
 }
 
-func NewRankingHandler(service service.RankingService) *RankingHandler {
-	return &RankingHandler{service: service}
+func NewRankingHandler(service service.RankingService, snapshotService service.RankingSnapshotService, userRepo repository.UserRepository) *RankingHandler {
+	return &RankingHandler{service: service, snapshotService: snapshotService, userRepo: userRepo}
 }
 
+// GetRankings ランキングを取得
+//
+//	?type=weekly|monthly|total|custom|term
+//	?metric=minutes|days|current_streak|longest_streak|points
+//	?from=2006-01-02&to=2006-01-02   (type=custom、to の日を含む)
+//	?term=spring&year=2025           (type=term、year を省略すると今年度)
+//	?compare=true                    直前の期間からの順位の変化を付ける
 func (h *RankingHandler) GetRankings(c *gin.Context) {
-	rankingType := c.DefaultQuery("type", "weekly")
-
-	var rankings []domain.UserRanking
-	var err error
-
-	switch rankingType {
-	case "weekly":
-		rankings, err = h.service.GetWeeklyRanking(c.Request.Context())
-	case "monthly":
-		rankings, err = h.service.GetMonthlyRanking(c.Request.Context())
-	case "total":
-		rankings, err = h.service.GetTotalRanking(c.Request.Context())
+	query := service.RankingQuery{
+		Period:          c.Query("type"),
+		Metric:          c.DefaultQuery("metric", service.RankingMetricMinutes),
+		From:            c.Query("from"),
+		To:              c.Query("to"),
+		Term:            c.Query("term"),
+		ComparePrevious: c.Query("compare") == "true",
+	}
+	if year := c.Query("year"); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid academic year"})
+			return
+		}
+		query.AcademicYear = y
+	}
+	switch {
+	case query.Period == "points":
+		// 旧形式の ?type=points は累計ポイントのランキング
+		query.Period, query.Metric = service.RankingPeriodTotal, service.RankingMetricPoints
+	case query.Period != "":
+	case query.From != "" || query.To != "":
+		query.Period = service.RankingPeriodCustom
+	case query.Term != "":
+		query.Period = service.RankingPeriodTerm
 	default:
-		// default to weekly
-		rankings, err = h.service.GetWeeklyRanking(c.Request.Context())
+		query.Period = service.RankingPeriodWeekly
 	}
 
+	result, err := h.service.GetRanking(c.Request.Context(), query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondRankingError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"rankings": rankings})
+	viewer, err := h.userRepo.FindByID(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	service.MaskPrivateRankings(viewer, result.Rankings)
+
+	c.Header(HeaderRankingCacheAge, strconv.Itoa(int(time.Since(result.ComputedAt).Seconds())))
+	c.JSON(http.StatusOK, result)
+}
+
+// GetSnapshotPeriods 保存済みの過去のランキングの期間一覧（?type=weekly|monthly）
+func (h *RankingHandler) GetSnapshotPeriods(c *gin.Context) {
+	period := c.DefaultQuery("type", service.RankingPeriodWeekly)
+	periods, err := h.snapshotService.ListPeriods(c.Request.Context(), period)
+	if err != nil {
+		respondRankingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"period": period, "periods": periods})
+}
+
+// GetSnapshot :from の日に始まる期間の過去のランキング（?type=weekly|monthly, ?metric=...）
+func (h *RankingHandler) GetSnapshot(c *gin.Context) {
+	result, err := h.snapshotService.GetSnapshot(c.Request.Context(),
+		c.DefaultQuery("type", service.RankingPeriodWeekly),
+		c.DefaultQuery("metric", service.RankingMetricMinutes),
+		c.Param("from"))
+	if err != nil {
+		respondRankingError(c, err)
+		return
+	}
+
+	viewer, err := h.userRepo.FindByID(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	service.MaskPrivateRankings(viewer, result.Rankings)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetUserHistory ユーザーの順位の推移（?type=weekly|monthly, ?metric=...）
+// 対象ユーザーと閲覧可否は UserVisibilityMiddleware で解決済み
+func (h *RankingHandler) GetUserHistory(c *gin.Context) {
+	period := c.DefaultQuery("type", service.RankingPeriodWeekly)
+	metric := c.DefaultQuery("metric", service.RankingMetricMinutes)
+	history, err := h.snapshotService.GetUserHistory(c.Request.Context(), c.GetUint("target_user_id"), period, metric)
+	if err != nil {
+		respondRankingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"period": period, "metric": metric, "history": history})
+}
+
+func respondRankingError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidRankingQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrRankingSnapshotNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ranking snapshot not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
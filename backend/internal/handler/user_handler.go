@@ -1,28 +1,39 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kasa021/watabe-lab-app/internal/domain"
 	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/service"
 )
 
 type UserHandler struct {
-	userRepo       repository.UserRepository
-	attendanceRepo repository.AttendanceRepository
+	userRepo          repository.UserRepository
+	attendanceRepo    repository.AttendanceRepository
+	attendanceService service.AttendanceService
+	rankings          service.RankingInvalidator
 }
 
-func NewUserHandler(userRepo repository.UserRepository, attendanceRepo repository.AttendanceRepository) *UserHandler {
+func NewUserHandler(userRepo repository.UserRepository, attendanceRepo repository.AttendanceRepository, attendanceService service.AttendanceService, rankings service.RankingInvalidator) *UserHandler {
 	return &UserHandler{
-		userRepo:       userRepo,
-		attendanceRepo: attendanceRepo,
+		userRepo:          userRepo,
+		attendanceRepo:    attendanceRepo,
+		attendanceService: attendanceService,
+		rankings:          rankings,
 	}
 }
 
 type UpdateProfileRequest struct {
 	DisplayName      string `json:"display_name"`
 	IsPresencePublic bool   `json:"is_presence_public"`
+	// ProfileVisibility 出席データの公開範囲（public/members/private）。省略時は変更しない
+	ProfileVisibility string `json:"profile_visibility"`
+	// IsLeaderboardPublic ランキングに参加するか。省略時は変更しない
+	IsLeaderboardPublic *bool `json:"is_leaderboard_public"`
 }
 
 // UpdateProfile プロフィール更新
@@ -45,48 +56,35 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	if req.ProfileVisibility != "" && !domain.IsValidVisibility(req.ProfileVisibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile_visibility"})
+		return
+	}
+
 	// 更新
 	user.DisplayName = req.DisplayName
 	user.IsPresencePublic = req.IsPresencePublic
+	if req.ProfileVisibility != "" {
+		user.ProfileVisibility = req.ProfileVisibility
+	}
+	if req.IsLeaderboardPublic != nil {
+		user.IsLeaderboardPublic = *req.IsLeaderboardPublic
+	}
 
 	if err := h.userRepo.Update(user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
 	}
+	// ランキングの表示名・公開範囲・参加の有無を反映する
+	h.rankings.Invalidate(user.ID)
 
 	c.JSON(http.StatusOK, user)
 }
 
 // GetAttendanceHeatmap ヒートマップ用データ取得
+// 対象ユーザーと閲覧可否は UserVisibilityMiddleware で解決済み
 func (h *UserHandler) GetAttendanceHeatmap(c *gin.Context) {
-	// ID指定があればそのユーザー、なければ自分
-	userIDStr := c.Param("id")
-	var targetUserID uint
-
-	if userIDStr == "" || userIDStr == "me" {
-		currentUserID, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-		targetUserID = currentUserID.(uint)
-	} else {
-		uid, err := strconv.ParseUint(userIDStr, 10, 32)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-			return
-		}
-		targetUserID = uint(uid)
-	}
-
-	// プライバシーチェック（他人の場合）
-	// (簡易実装: 自分以外は見れない or 公開設定を見るなど必要だが、要件的にそこまで厳密でなくて良いなら一旦スルー)
-	// 要件「自分がいつ研究室にきたのか」がメインなので、とりあえず自分のデータは見れるようにする。
-	// 他人のデータを見る機能はまだ明示されていないが、一応APIは汎用的にしておく。
-
-	// limit := 365 // 過去1年分
-	// TODO: リポジトリ側のメソッドで期間指定できるようにしても良い。現状は全期間か簡易実装。
-	// GetDailyAttendanceCounts は期間指定なし（全期間）になっているはず。
+	targetUserID := c.GetUint("target_user_id")
 
 	// データ取得
 	dailies, err := h.attendanceRepo.GetDailyAttendanceCounts(c.Request.Context(), targetUserID)
@@ -113,3 +111,29 @@ func (h *UserHandler) GetAttendanceHeatmap(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"heatmap": response})
 }
+
+// GetSessions セッション履歴を取得
+// ?from=YYYY-MM-DD&to=YYYY-MM-DD&method=wifi&cursor=...&limit=20
+// 対象ユーザーと閲覧可否は UserVisibilityMiddleware で解決済み
+func (h *UserHandler) GetSessions(c *gin.Context) {
+	targetUserID := c.GetUint("target_user_id")
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	history, err := h.attendanceService.GetSessionHistory(c.Request.Context(), targetUserID, service.SessionHistoryQuery{
+		From:          c.Query("from"),
+		To:            c.Query("to"),
+		CheckInMethod: c.Query("method"),
+		Cursor:        c.Query("cursor"),
+		Limit:         limit,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSessionQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
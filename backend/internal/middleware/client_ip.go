@@ -0,0 +1,14 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// ConfigureClientIP c.ClientIP() の判定方法を設定する。
+// 信頼するプロキシ（IPまたはCIDR）からのリクエストに限り header を参照し、それ以外は接続元アドレスを使う
+// （IP制限付きチェックインで X-Forwarded-For を偽装されないようにするため）
+func ConfigureClientIP(r *gin.Engine, trustedProxies []string, header string) error {
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return err
+	}
+	r.RemoteIPHeaders = []string{header}
+	return nil
+}
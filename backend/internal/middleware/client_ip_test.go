@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestConfigureClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		want           string
+	}{
+		{"信頼するプロキシからは転送ヘッダーを使う", []string{"172.16.0.0/12"}, "172.18.0.5:40000", "192.0.2.10"},
+		{"信頼しない接続元の転送ヘッダーは無視する", []string{"172.16.0.0/12"}, "203.0.113.7:40000", "203.0.113.7"},
+		{"プロキシ未設定なら常に接続元", nil, "172.18.0.5:40000", "172.18.0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			if err := ConfigureClientIP(r, tt.trustedProxies, "X-Forwarded-For"); err != nil {
+				t.Fatal(err)
+			}
+			r.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "192.0.2.10")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("ClientIP = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigureClientIP_InvalidProxy(t *testing.T) {
+	if err := ConfigureClientIP(gin.New(), []string{"not-an-ip"}, "X-Forwarded-For"); err == nil {
+		t.Error("invalid proxy was accepted")
+	}
+}
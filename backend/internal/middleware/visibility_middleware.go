@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+)
+
+// UserVisibilityMiddleware パスの :id（"me" 可）のユーザーの出席データを閲覧できるか確認するミドルウェア
+// 閲覧できる場合は対象ユーザーIDを "target_user_id" としてコンテキストに設定する
+func UserVisibilityMiddleware(userRepo repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		viewerID := c.GetUint("user_id")
+
+		targetID := viewerID
+		if idStr := c.Param("id"); idStr != "me" {
+			id, err := strconv.ParseUint(idStr, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": gin.H{
+						"code":    "INVALID_USER_ID",
+						"message": "ユーザーIDが不正です",
+					},
+				})
+				c.Abort()
+				return
+			}
+			targetID = uint(id)
+		}
+
+		if targetID != viewerID {
+			target, err := userRepo.FindByID(targetID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": gin.H{
+						"code":    "USER_NOT_FOUND",
+						"message": "ユーザーが見つかりません",
+					},
+				})
+				c.Abort()
+				return
+			}
+
+			viewer, err := userRepo.FindByID(viewerID)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": gin.H{
+						"code":    "UNAUTHORIZED",
+						"message": "認証が必要です",
+					},
+				})
+				c.Abort()
+				return
+			}
+
+			if !target.IsVisibleTo(viewer) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": gin.H{
+						"code":    "PRIVATE_PROFILE",
+						"message": "このユーザーの出席データは公開されていません",
+					},
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set("target_user_id", targetID)
+		c.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepo ID で検索できるだけの UserRepository
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[uint]domain.User
+}
+
+func (r fakeUserRepo) FindByID(id uint) (*domain.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+// newVisibilityRouter main.go と同じく /users/:id 以下に UserVisibilityMiddleware を掛けたルーター。
+// ハンドラーは対象ユーザーの ID を返すだけにする
+func newVisibilityRouter(viewerID uint, users map[uint]domain.User) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", viewerID)
+		c.Next()
+	})
+	group := r.Group("/users/:id")
+	group.Use(UserVisibilityMiddleware(fakeUserRepo{users: users}))
+	target := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"target_user_id": c.GetUint("target_user_id")}) }
+	group.GET("/heatmap", target)
+	group.GET("/achievements", target)
+	group.GET("/ranking-history", target)
+	return r
+}
+
+func TestUserVisibilityMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	users := map[uint]domain.User{
+		1: {ID: 1, Role: "student", IsActive: true, ProfileVisibility: domain.VisibilityPrivate},
+		2: {ID: 2, Role: "student", IsActive: true, ProfileVisibility: domain.VisibilityMembers},
+		3: {ID: 3, Role: "student", IsActive: true, ProfileVisibility: domain.VisibilityPublic},
+		4: {ID: 4, Role: "student", IsActive: false, ProfileVisibility: domain.VisibilityMembers},
+		9: {ID: 9, Role: "teacher", IsActive: true, ProfileVisibility: domain.VisibilityMembers},
+	}
+	tests := []struct {
+		name     string
+		viewerID uint
+		path     string
+		want     int
+	}{
+		{"非公開のユーザーは他のメンバーに見せない", 2, "/users/1", http.StatusForbidden},
+		{"非公開でも本人は見られる", 1, "/users/1", http.StatusOK},
+		{"非公開でも教員は見られる", 9, "/users/1", http.StatusOK},
+		{"メンバー限定は在籍中のメンバーに見せる", 3, "/users/2", http.StatusOK},
+		{"メンバー限定は在籍していないユーザーに見せない", 4, "/users/2", http.StatusForbidden},
+		{"公開は在籍していないユーザーにも見せる", 4, "/users/3", http.StatusOK},
+		{"me は本人", 1, "/users/me", http.StatusOK},
+		{"存在しないユーザー", 2, "/users/99", http.StatusNotFound},
+		{"不正な ID", 2, "/users/abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newVisibilityRouter(tt.viewerID, users)
+			for _, endpoint := range []string{"/heatmap", "/achievements", "/ranking-history"} {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path+endpoint, nil))
+				if w.Code != tt.want {
+					t.Errorf("%s%s: status = %d, want %d", tt.path, endpoint, w.Code, tt.want)
+				}
+			}
+		})
+	}
+}
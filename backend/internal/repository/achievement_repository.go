@@ -8,11 +8,32 @@ import (
 )
 
 type AchievementRepository interface {
+	// Transaction は fn 内で渡されたリポジトリを使った操作を1つのトランザクションで実行する。
+	// 称号の付与と報酬のポイントを一緒に記録するため、同じトランザクションの PointRepository も渡す
+	Transaction(ctx context.Context, fn func(repo AchievementRepository, pointRepo PointRepository) error) error
 	FindAll(ctx context.Context) ([]domain.Achievement, error)
+	FindActive(ctx context.Context) ([]domain.Achievement, error)
 	FindByCode(ctx context.Context, code string) (*domain.Achievement, error)
 	CreateUserAchievement(ctx context.Context, ua *domain.UserAchievement) error
 	GetUserAchievements(ctx context.Context, userID uint) ([]domain.UserAchievement, error)
 	HasUnlocked(ctx context.Context, userID uint, achievementID uint) (bool, error)
+
+	// 称号マスタの管理
+	FindByID(ctx context.Context, id uint) (*domain.Achievement, error)
+	Create(ctx context.Context, achievement *domain.Achievement) error
+	Update(ctx context.Context, achievement *domain.Achievement) error
+	Delete(ctx context.Context, id uint) error
+	// CountUnlocked は称号を獲得したユーザー数を返す
+	CountUnlocked(ctx context.Context, achievementID uint) (int64, error)
+	// Reorder は ids の順に display_order を 1 から振り直す
+	Reorder(ctx context.Context, ids []uint) error
+
+	// 称号の手動付与
+	// DeleteUserAchievement は獲得記録を削除し、その称号の報酬のポイントも取り消す
+	DeleteUserAchievement(ctx context.Context, userID uint, achievementID uint) error
+	CreateAwardLog(ctx context.Context, log *domain.AchievementAwardLog) error
+	// GetAwardLogs は手動付与・取り消しの履歴を新しい順に返す（userID が 0 の場合は全ユーザー）
+	GetAwardLogs(ctx context.Context, userID uint, limit int) ([]domain.AchievementAwardLog, error)
 }
 
 type achievementRepository struct {
@@ -23,9 +44,26 @@ func NewAchievementRepository(db *gorm.DB) AchievementRepository {
 	return &achievementRepository{db: db}
 }
 
+func (r *achievementRepository) Transaction(ctx context.Context, fn func(repo AchievementRepository, pointRepo PointRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&achievementRepository{db: tx}, &pointRepository{db: tx})
+	})
+}
+
 func (r *achievementRepository) FindAll(ctx context.Context) ([]domain.Achievement, error) {
 	var achievements []domain.Achievement
-	if err := r.db.WithContext(ctx).Find(&achievements).Error; err != nil {
+	if err := r.db.WithContext(ctx).Order("display_order, id").Find(&achievements).Error; err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
+
+func (r *achievementRepository) FindActive(ctx context.Context) ([]domain.Achievement, error) {
+	var achievements []domain.Achievement
+	if err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("display_order, id").
+		Find(&achievements).Error; err != nil {
 		return nil, err
 	}
 	return achievements, nil
@@ -64,3 +102,85 @@ func (r *achievementRepository) HasUnlocked(ctx context.Context, userID uint, ac
 	}
 	return count > 0, nil
 }
+
+func (r *achievementRepository) FindByID(ctx context.Context, id uint) (*domain.Achievement, error) {
+	var achievement domain.Achievement
+	if err := r.db.WithContext(ctx).First(&achievement, id).Error; err != nil {
+		return nil, err
+	}
+	return &achievement, nil
+}
+
+func (r *achievementRepository) Create(ctx context.Context, achievement *domain.Achievement) error {
+	// is_active はゼロ値（false）だと DB のデフォルト値（true）で作成されるため、作成後に書き戻す
+	isActive := achievement.IsActive
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(achievement).Error; err != nil {
+			return err
+		}
+		if achievement.IsActive == isActive {
+			return nil
+		}
+		achievement.IsActive = isActive
+		return tx.Model(achievement).Update("is_active", isActive).Error
+	})
+}
+
+func (r *achievementRepository) Update(ctx context.Context, achievement *domain.Achievement) error {
+	return r.db.WithContext(ctx).Save(achievement).Error
+}
+
+func (r *achievementRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.Achievement{}, id).Error
+}
+
+func (r *achievementRepository) CountUnlocked(ctx context.Context, achievementID uint) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&domain.UserAchievement{}).
+		Where("achievement_id = ?", achievementID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *achievementRepository) Reorder(ctx context.Context, ids []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&domain.Achievement{}).
+				Where("id = ?", id).
+				Update("display_order", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *achievementRepository) DeleteUserAchievement(ctx context.Context, userID uint, achievementID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND achievement_id = ?", userID, achievementID).
+			Delete(&domain.UserAchievement{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND achievement_id = ? AND source = ?", userID, achievementID, domain.PointSourceAchievement).
+			Delete(&domain.PointTransaction{}).Error
+	})
+}
+
+func (r *achievementRepository) CreateAwardLog(ctx context.Context, log *domain.AchievementAwardLog) error {
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+func (r *achievementRepository) GetAwardLogs(ctx context.Context, userID uint, limit int) ([]domain.AchievementAwardLog, error) {
+	var logs []domain.AchievementAwardLog
+	query := r.db.WithContext(ctx).Preload("Achievement").Preload("Actor")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
@@ -8,13 +8,54 @@ import (
 	"gorm.io/gorm"
 )
 
+// SessionFilter セッション履歴の検索条件
+type SessionFilter struct {
+	UserID        uint
+	From          *time.Time // check_in_at >= From
+	To            *time.Time // check_in_at < To
+	CheckInMethod string
+	// カーソル: 前ページ最後のセッションより後（check_in_at, id の降順で）を取得する
+	AfterCheckInAt *time.Time
+	AfterID        uint
+	Limit          int
+}
+
+// SessionTotals セッション履歴の集計値
+type SessionTotals struct {
+	SessionCount int64 `json:"session_count"`
+	TotalMinutes int64 `json:"total_minutes"`
+}
+
 type AttendanceRepository interface {
+	// Transaction は fn 内で渡されたリポジトリを使った操作を1つのトランザクションで実行する
+	Transaction(ctx context.Context, fn func(repo AttendanceRepository) error) error
 	Create(ctx context.Context, log *domain.CheckInLog) error
 	Update(ctx context.Context, log *domain.CheckInLog) error
+	// CloseSession はセッションがまだ終了していない場合に限り終了時刻・滞在時間を書き込み、書き込んだかを返す
+	CloseSession(ctx context.Context, log *domain.CheckInLog) (bool, error)
+	FindByID(ctx context.Context, id uint) (*domain.CheckInLog, error)
+	Delete(ctx context.Context, id uint) error
+	HasOverlappingSession(ctx context.Context, userID uint, from, to time.Time, excludeID uint) (bool, error)
+	CreateAuditLog(ctx context.Context, audit *domain.AttendanceAuditLog) error
+	GetAuditLogs(ctx context.Context, userID uint, limit int) ([]domain.AttendanceAuditLog, error)
 	GetActiveCheckIn(ctx context.Context, userID uint) (*domain.CheckInLog, error)
 	GetAllActiveCheckIns(ctx context.Context) ([]domain.CheckInLog, error)
-	GetUserRanking(ctx context.Context, from, to time.Time) ([]domain.UserRanking, error)
+	GetStaleCheckIns(ctx context.Context, checkedInBefore time.Time) ([]domain.CheckInLog, error)
+	// GetRankedOpenSessions はランキングに参加するユーザーの終了していないセッションを返す
+	GetRankedOpenSessions(ctx context.Context) ([]domain.CheckInLog, error)
+	// GetUserRanking は期間 [from, to) の滞在時間の合計をユーザーごとに返す。excludeHolidays の場合は休日の分を除く。
+	// 無効化されたユーザーとランキングに参加しないユーザーは含まない。userIDs を指定した場合はそのユーザーのみ
+	GetUserRanking(ctx context.Context, from, to time.Time, excludeHolidays bool, userIDs ...uint) ([]domain.UserRanking, error)
 	GetDailyAttendanceCounts(ctx context.Context, userID uint) ([]domain.DailyAttendance, error)
+	// GetAttendanceDays は期間 [from, to) の出席日をユーザー・日付順に返す（user_id と attendance_date のみ）。
+	// userIDs を指定した場合はそのユーザーのみ
+	GetAttendanceDays(ctx context.Context, from, to time.Time, excludeHolidays bool, userIDs ...uint) ([]domain.DailyAttendance, error)
+	GetClosedSessions(ctx context.Context, from, to time.Time, userIDs ...uint) ([]domain.CheckInLog, error)
+	GetUserHistory(ctx context.Context, userID uint) ([]domain.CheckInLog, error)
+	ListSessions(ctx context.Context, filter SessionFilter) ([]domain.CheckInLog, error)
+	SumSessions(ctx context.Context, filter SessionFilter) (*SessionTotals, error)
+	UpsertDailyAttendance(ctx context.Context, daily *domain.DailyAttendance) error
+	DeleteDailyAttendances(ctx context.Context, from, to time.Time, userIDs ...uint) error
 }
 
 type attendanceRepository struct {
@@ -25,6 +66,12 @@ func NewAttendanceRepository(db *gorm.DB) AttendanceRepository {
 	return &attendanceRepository{db: db}
 }
 
+func (r *attendanceRepository) Transaction(ctx context.Context, fn func(repo AttendanceRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&attendanceRepository{db: tx})
+	})
+}
+
 func (r *attendanceRepository) Create(ctx context.Context, log *domain.CheckInLog) error {
 	return r.db.WithContext(ctx).Create(log).Error
 }
@@ -33,6 +80,66 @@ func (r *attendanceRepository) Update(ctx context.Context, log *domain.CheckInLo
 	return r.db.WithContext(ctx).Save(log).Error
 }
 
+// CloseSession 手動のチェックアウトと自動終了が同時に行われても、先に終了した方の記録を上書きしないよう
+// check_out_at IS NULL を条件に更新する
+func (r *attendanceRepository) CloseSession(ctx context.Context, log *domain.CheckInLog) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(log).
+		Where("check_out_at IS NULL").
+		Updates(map[string]interface{}{
+			"check_out_at":     log.CheckOutAt,
+			"duration_minutes": log.DurationMinutes,
+			"is_auto_checkout": log.IsAutoCheckout,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *attendanceRepository) FindByID(ctx context.Context, id uint) (*domain.CheckInLog, error) {
+	var log domain.CheckInLog
+	if err := r.db.WithContext(ctx).First(&log, id).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (r *attendanceRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.CheckInLog{}, id).Error
+}
+
+// HasOverlappingSession [from, to) と重なるユーザーのセッションがあるか（excludeID のログは除く）
+// チェックアウトしていないセッションは終了時刻が無限先とみなす
+func (r *attendanceRepository) HasOverlappingSession(ctx context.Context, userID uint, from, to time.Time, excludeID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&domain.CheckInLog{}).
+		Where("user_id = ? AND id <> ?", userID, excludeID).
+		Where("check_in_at < ? AND (check_out_at IS NULL OR check_out_at > ?)", to, from).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *attendanceRepository) CreateAuditLog(ctx context.Context, audit *domain.AttendanceAuditLog) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
+
+// GetAuditLogs 修正履歴を新しい順に取得（userID が 0 の場合は全ユーザー）
+func (r *attendanceRepository) GetAuditLogs(ctx context.Context, userID uint, limit int) ([]domain.AttendanceAuditLog, error) {
+	var audits []domain.AttendanceAuditLog
+	query := r.db.WithContext(ctx).Preload("Actor")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&audits).Error; err != nil {
+		return nil, err
+	}
+	return audits, nil
+}
+
 func (r *attendanceRepository) GetActiveCheckIn(ctx context.Context, userID uint) (*domain.CheckInLog, error) {
 	var log domain.CheckInLog
 	if err := r.db.WithContext(ctx).
@@ -56,15 +163,51 @@ func (r *attendanceRepository) GetAllActiveCheckIns(ctx context.Context) ([]doma
 	return logs, nil
 }
 
-func (r *attendanceRepository) GetUserRanking(ctx context.Context, from, to time.Time) ([]domain.UserRanking, error) {
-	var results []domain.UserRanking
-	// JOINしてUser情報も一度に取得
+// GetStaleCheckIns 指定時刻より前にチェックインしたまま終了していないログを取得
+func (r *attendanceRepository) GetStaleCheckIns(ctx context.Context, checkedInBefore time.Time) ([]domain.CheckInLog, error) {
+	var logs []domain.CheckInLog
 	if err := r.db.WithContext(ctx).
-		Table("check_in_logs").
-		Select("check_in_logs.user_id, SUM(check_in_logs.duration_minutes) as total_duration, users.display_name, users.username").
+		Preload("User").
+		Where("check_out_at IS NULL AND check_in_at < ?", checkedInBefore).
+		Order("check_in_at").
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func (r *attendanceRepository) GetRankedOpenSessions(ctx context.Context) ([]domain.CheckInLog, error) {
+	var logs []domain.CheckInLog
+	if err := r.db.WithContext(ctx).
+		Preload("User").
 		Joins("JOIN users ON users.id = check_in_logs.user_id").
-		Where("check_in_logs.check_in_at BETWEEN ? AND ?", from, to).
-		Group("check_in_logs.user_id, users.display_name, users.username").
+		Where("check_in_logs.check_out_at IS NULL").
+		Where("users.is_active = true AND users.is_leaderboard_public = true").
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// GetUserRanking 期間 [from, to) の日次集計から滞在時間を合計する。
+// 日をまたぐ滞在は各日に按分済みなので、期間の境界をまたぐセッションも期間内の分だけが数えられる。
+func (r *attendanceRepository) GetUserRanking(ctx context.Context, from, to time.Time, excludeHolidays bool, userIDs ...uint) ([]domain.UserRanking, error) {
+	var results []domain.UserRanking
+	// JOINしてUser情報も一度に取得
+	query := r.db.WithContext(ctx).
+		Table("daily_attendances").
+		Select("daily_attendances.user_id, SUM(daily_attendances.total_duration_minutes) as total_duration, COUNT(*) as attendance_days, users.display_name, users.username, users.profile_visibility").
+		Joins("JOIN users ON users.id = daily_attendances.user_id").
+		Where("users.is_active = true AND users.is_leaderboard_public = true").
+		Where("daily_attendances.attendance_date >= ? AND daily_attendances.attendance_date < ?", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if excludeHolidays {
+		query = query.Where("daily_attendances.is_holiday = false")
+	}
+	if len(userIDs) > 0 {
+		query = query.Where("daily_attendances.user_id IN ?", userIDs)
+	}
+	if err := query.
+		Group("daily_attendances.user_id, users.display_name, users.username, users.profile_visibility").
 		Order("total_duration DESC").
 		Scan(&results).Error; err != nil {
 		return nil, err
@@ -73,43 +216,149 @@ func (r *attendanceRepository) GetUserRanking(ctx context.Context, from, to time
 	return results, nil
 }
 
+// GetDailyAttendanceCounts daily_attendances から日別の集計を新しい順に取得
 func (r *attendanceRepository) GetDailyAttendanceCounts(ctx context.Context, userID uint) ([]domain.DailyAttendance, error) {
-	// 簡易的に check_in_logs から集計して返す
-	// 本来は daily_attendances テーブルがあるならそこから取るべきだが、
-	// 現状の check_in_logs から日別に集計するクエリを書く
-	// PostgreSQLの DATE_TRUNC を使用
+	var dailies []domain.DailyAttendance
+	// first_check_in_at / last_check_out_at は TIME 型で time.Time に読み込めないため除外する
+	if err := r.db.WithContext(ctx).
+		Select("id, user_id, attendance_date, total_duration_minutes, check_in_count, points, is_holiday, created_at, updated_at").
+		Where("user_id = ?", userID).
+		Order("attendance_date DESC").
+		Find(&dailies).Error; err != nil {
+		return nil, err
+	}
+	return dailies, nil
+}
 
-	type Result struct {
-		Date         time.Time `json:"date"`
-		ThinkingTime int       `json:"duration"` // minute
-		Count        int       `json:"count"`
+func (r *attendanceRepository) GetAttendanceDays(ctx context.Context, from, to time.Time, excludeHolidays bool, userIDs ...uint) ([]domain.DailyAttendance, error) {
+	var dailies []domain.DailyAttendance
+	query := r.db.WithContext(ctx).
+		Select("user_id, attendance_date").
+		Where("attendance_date >= ? AND attendance_date < ?", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if excludeHolidays {
+		query = query.Where("is_holiday = false")
+	}
+	if len(userIDs) > 0 {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+	if err := query.Order("user_id, attendance_date").Find(&dailies).Error; err != nil {
+		return nil, err
 	}
+	return dailies, nil
+}
 
-	var results []Result
+// GetClosedSessions 期間 [from, to) と滞在時間が重なるチェックアウト済みのログを取得
+// （日をまたぐセッションも含む）。userIDs を指定した場合はそのユーザーのみに絞り込む
+func (r *attendanceRepository) GetClosedSessions(ctx context.Context, from, to time.Time, userIDs ...uint) ([]domain.CheckInLog, error) {
+	var logs []domain.CheckInLog
+	query := r.db.WithContext(ctx).
+		Where("check_out_at IS NOT NULL AND check_in_at < ? AND check_out_at > ?", to, from)
+	if len(userIDs) > 0 {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+	if err := query.Order("user_id, check_in_at").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
 
-	// 日付ごとの滞在時間と回数を集計
-	// duration_minutes が NULL の場合は 0 として扱う (COALESCE)
-	err := r.db.WithContext(ctx).
-		Table("check_in_logs").
-		Select("DATE(check_in_at) as date, SUM(COALESCE(duration_minutes, 0)) as thinking_time, COUNT(*) as count").
-		Where("user_id = ?", userID).
-		Group("DATE(check_in_at)").
-		Order("date DESC").
-		Scan(&results).Error
+// GetUserHistory ユーザーのチェックアウト済みログを全期間、古い順に取得
+func (r *attendanceRepository) GetUserHistory(ctx context.Context, userID uint) ([]domain.CheckInLog, error) {
+	var logs []domain.CheckInLog
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND check_out_at IS NOT NULL", userID).
+		Order("check_in_at").
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
 
-	if err != nil {
+// ListSessions セッション履歴を新しい順に取得
+func (r *attendanceRepository) ListSessions(ctx context.Context, filter SessionFilter) ([]domain.CheckInLog, error) {
+	var logs []domain.CheckInLog
+	query := r.sessionQuery(ctx, filter)
+	if filter.AfterCheckInAt != nil {
+		query = query.Where("(check_in_at < ? OR (check_in_at = ? AND id < ?))", *filter.AfterCheckInAt, *filter.AfterCheckInAt, filter.AfterID)
+	}
+	if err := query.
+		Order("check_in_at DESC, id DESC").
+		Limit(filter.Limit).
+		Find(&logs).Error; err != nil {
 		return nil, err
 	}
+	return logs, nil
+}
 
-	// domain.DailyAttendance に変換
-	var dailies []domain.DailyAttendance
-	for _, res := range results {
-		dailies = append(dailies, domain.DailyAttendance{
-			AttendanceDate:       res.Date,
-			TotalDurationMinutes: res.ThinkingTime,
-			CheckInCount:         res.Count,
-		})
+// SumSessions 検索条件に一致するセッションの件数と合計滞在時間（カーソルは無視する）
+func (r *attendanceRepository) SumSessions(ctx context.Context, filter SessionFilter) (*SessionTotals, error) {
+	var totals SessionTotals
+	if err := r.sessionQuery(ctx, filter).
+		Select("COUNT(*) AS session_count, COALESCE(SUM(duration_minutes), 0) AS total_minutes").
+		Scan(&totals).Error; err != nil {
+		return nil, err
 	}
+	return &totals, nil
+}
 
-	return dailies, nil
+func (r *attendanceRepository) sessionQuery(ctx context.Context, filter SessionFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Model(&domain.CheckInLog{}).
+		Where("user_id = ?", filter.UserID)
+	if filter.From != nil {
+		query = query.Where("check_in_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("check_in_at < ?", *filter.To)
+	}
+	if filter.CheckInMethod != "" {
+		query = query.Where("check_in_method = ?", filter.CheckInMethod)
+	}
+	return query
+}
+
+// UpsertDailyAttendance 日次出席記録を (user_id, attendance_date) 単位で作成または更新
+func (r *attendanceRepository) UpsertDailyAttendance(ctx context.Context, daily *domain.DailyAttendance) error {
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO daily_attendances (
+			user_id, attendance_date, total_duration_minutes, check_in_count,
+			first_check_in_at, last_check_out_at, points, is_holiday, created_at, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+		ON CONFLICT (user_id, attendance_date) DO UPDATE SET
+			total_duration_minutes = EXCLUDED.total_duration_minutes,
+			check_in_count = EXCLUDED.check_in_count,
+			first_check_in_at = EXCLUDED.first_check_in_at,
+			last_check_out_at = EXCLUDED.last_check_out_at,
+			points = EXCLUDED.points,
+			is_holiday = EXCLUDED.is_holiday,
+			updated_at = NOW()`,
+		daily.UserID,
+		daily.AttendanceDate.Format("2006-01-02"),
+		daily.TotalDurationMinutes,
+		daily.CheckInCount,
+		formatTimeOfDay(daily.FirstCheckInAt),
+		formatTimeOfDay(daily.LastCheckOutAt),
+		daily.Points,
+		daily.IsHoliday,
+	).Error
+}
+
+// DeleteDailyAttendances 期間内の日次出席記録を削除
+// userIDs を指定した場合はそのユーザーのみに絞り込む
+func (r *attendanceRepository) DeleteDailyAttendances(ctx context.Context, from, to time.Time, userIDs ...uint) error {
+	query := r.db.WithContext(ctx).
+		Where("attendance_date >= ? AND attendance_date < ?", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if len(userIDs) > 0 {
+		query = query.Where("user_id IN ?", userIDs)
+	}
+	return query.Delete(&domain.DailyAttendance{}).Error
+}
+
+// formatTimeOfDay TIME 型カラムに書き込むために時刻部分だけを文字列化する
+func formatTimeOfDay(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format("15:04:05")
 }
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CorrectionRequestRepository interface {
+	// Transaction は fn 内で渡されたリポジトリを使った操作を1つのトランザクションで実行する。
+	// 承認時に出席記録も変更するため、同じトランザクションの AttendanceRepository も渡す
+	Transaction(ctx context.Context, fn func(repo CorrectionRequestRepository, logRepo AttendanceRepository) error) error
+	Create(ctx context.Context, req *domain.AttendanceCorrectionRequest) error
+	Update(ctx context.Context, req *domain.AttendanceCorrectionRequest) error
+	FindByID(ctx context.Context, id uint) (*domain.AttendanceCorrectionRequest, error)
+	// FindByIDForUpdate は申請の行をロックして取得する（トランザクション内で使う）
+	FindByIDForUpdate(ctx context.Context, id uint) (*domain.AttendanceCorrectionRequest, error)
+	FindByUser(ctx context.Context, userID uint) ([]domain.AttendanceCorrectionRequest, error)
+	FindByStatus(ctx context.Context, status string) ([]domain.AttendanceCorrectionRequest, error)
+	HasPendingForLog(ctx context.Context, checkInLogID uint) (bool, error)
+}
+
+type correctionRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewCorrectionRequestRepository(db *gorm.DB) CorrectionRequestRepository {
+	return &correctionRequestRepository{db: db}
+}
+
+func (r *correctionRequestRepository) Transaction(ctx context.Context, fn func(repo CorrectionRequestRepository, logRepo AttendanceRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&correctionRequestRepository{db: tx}, &attendanceRepository{db: tx})
+	})
+}
+
+func (r *correctionRequestRepository) Create(ctx context.Context, req *domain.AttendanceCorrectionRequest) error {
+	return r.db.WithContext(ctx).Omit("User", "Reviewer").Create(req).Error
+}
+
+func (r *correctionRequestRepository) Update(ctx context.Context, req *domain.AttendanceCorrectionRequest) error {
+	return r.db.WithContext(ctx).Omit("User", "Reviewer").Save(req).Error
+}
+
+func (r *correctionRequestRepository) FindByID(ctx context.Context, id uint) (*domain.AttendanceCorrectionRequest, error) {
+	var req domain.AttendanceCorrectionRequest
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Reviewer").
+		First(&req, id).Error; err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// FindByIDForUpdate 同じ申請を同時に承認・却下した場合、後の方は先の方のコミットを待ってから状態を読む
+func (r *correctionRequestRepository) FindByIDForUpdate(ctx context.Context, id uint) (*domain.AttendanceCorrectionRequest, error) {
+	var req domain.AttendanceCorrectionRequest
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("User").
+		First(&req, id).Error; err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *correctionRequestRepository) FindByUser(ctx context.Context, userID uint) ([]domain.AttendanceCorrectionRequest, error) {
+	var reqs []domain.AttendanceCorrectionRequest
+	if err := r.db.WithContext(ctx).
+		Preload("Reviewer").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&reqs).Error; err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+// FindByStatus 指定した状態の申請を古い順に取得（承認待ちを申請順に処理できるように）
+func (r *correctionRequestRepository) FindByStatus(ctx context.Context, status string) ([]domain.AttendanceCorrectionRequest, error) {
+	var reqs []domain.AttendanceCorrectionRequest
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Preload("Reviewer").
+		Where("status = ?", status).
+		Order("created_at").
+		Find(&reqs).Error; err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+func (r *correctionRequestRepository) HasPendingForLog(ctx context.Context, checkInLogID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&domain.AttendanceCorrectionRequest{}).
+		Where("check_in_log_id = ? AND status = ?", checkInLogID, domain.CorrectionStatusPending).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PointRepository interface {
+	// CreateTransaction はポイント取引を記録する。同じ称号の報酬が既にある場合は何もしない
+	CreateTransaction(ctx context.Context, tx *domain.PointTransaction) error
+	GetBalance(ctx context.Context, userID uint) (int, error)
+	// GetLedger はポイント台帳を新しい順に返す
+	GetLedger(ctx context.Context, userID uint, limit int) ([]domain.PointLedgerEntry, error)
+	// GetPointsRanking は期間 [from, to) に得たポイントの合計をユーザーごとに返す。
+	// 無効化されたユーザーとランキングに参加しないユーザーは含まない。userIDs を指定した場合はそのユーザーのみ
+	GetPointsRanking(ctx context.Context, from, to time.Time, userIDs ...uint) ([]domain.UserRanking, error)
+}
+
+type pointRepository struct {
+	db *gorm.DB
+}
+
+func NewPointRepository(db *gorm.DB) PointRepository {
+	return &pointRepository{db: db}
+}
+
+func (r *pointRepository) CreateTransaction(ctx context.Context, tx *domain.PointTransaction) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(tx).Error
+}
+
+func (r *pointRepository) GetBalance(ctx context.Context, userID uint) (int, error) {
+	var balance int
+	if err := r.db.WithContext(ctx).
+		Table("point_ledger").
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ?", userID).
+		Scan(&balance).Error; err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+func (r *pointRepository) GetLedger(ctx context.Context, userID uint, limit int) ([]domain.PointLedgerEntry, error) {
+	var entries []domain.PointLedgerEntry
+	if err := r.db.WithContext(ctx).
+		Table("point_ledger").
+		Where("user_id = ?", userID).
+		// 出席ポイントは集計し直すたびに created_at が変わるため、出席日で並べる
+		Order("COALESCE(attendance_date::timestamptz, created_at) DESC").
+		Limit(limit).
+		Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *pointRepository) GetPointsRanking(ctx context.Context, from, to time.Time, userIDs ...uint) ([]domain.UserRanking, error) {
+	var results []domain.UserRanking
+	query := r.db.WithContext(ctx).
+		Table("point_ledger").
+		Select("point_ledger.user_id, SUM(point_ledger.amount) as total_points, users.display_name, users.username, users.profile_visibility").
+		Joins("JOIN users ON users.id = point_ledger.user_id").
+		Where("users.is_active = true AND users.is_leaderboard_public = true").
+		// 出席ポイントは出席日、それ以外は付与日で期間に含めるか判定する
+		Where("COALESCE(point_ledger.attendance_date, point_ledger.created_at::date) >= ? AND COALESCE(point_ledger.attendance_date, point_ledger.created_at::date) < ?",
+			from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if len(userIDs) > 0 {
+		query = query.Where("point_ledger.user_id IN ?", userIDs)
+	}
+	if err := query.
+		Group("point_ledger.user_id, users.display_name, users.username, users.profile_visibility").
+		Order("total_points DESC").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RankingSnapshotRepository interface {
+	// HasSnapshot は期間のスナップショットが保存済みかを返す
+	HasSnapshot(ctx context.Context, period string, periodStart time.Time) (bool, error)
+	// GetLatestPeriod は最後に保存した期間を返す（無い場合は gorm.ErrRecordNotFound）
+	GetLatestPeriod(ctx context.Context, period string) (*domain.RankingSnapshotPeriod, error)
+	// CreateSnapshots は期間と、その期間のスナップショットをまとめて保存する。
+	// 順位の無い期間も保存済みとして記録する。保存済みの行は何もしない
+	CreateSnapshots(ctx context.Context, period *domain.RankingSnapshotPeriod, snapshots []domain.RankingSnapshot) error
+	// GetPeriods は保存済みの期間を新しい順に返す
+	GetPeriods(ctx context.Context, period string, limit int) ([]domain.RankingSnapshotPeriod, error)
+	// GetSnapshot は期間の指標のランキングを順位順に返す。
+	// 無効化されたユーザーと、現在ランキングに参加していないユーザーは含まない
+	GetSnapshot(ctx context.Context, period, metric string, periodStart time.Time) ([]domain.UserRanking, error)
+	// GetUserHistory はユーザーの順位の推移を新しい順に返す。
+	// 順位は無効化されたユーザーと、現在ランキングに参加していないユーザーを除いて数え直す
+	GetUserHistory(ctx context.Context, userID uint, period, metric string, limit int) ([]domain.RankingSnapshot, error)
+	// GetPeriodTotals は期間 [from, to) の日次集計とポイント台帳をユーザーごとに合計する。
+	// 表示するユーザーは参照時に絞り込むため、無効化されたユーザーとランキングに参加しないユーザーも含める
+	GetPeriodTotals(ctx context.Context, from, to time.Time, excludeHolidays bool) ([]domain.UserRanking, error)
+}
+
+type rankingSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewRankingSnapshotRepository(db *gorm.DB) RankingSnapshotRepository {
+	return &rankingSnapshotRepository{db: db}
+}
+
+func (r *rankingSnapshotRepository) HasSnapshot(ctx context.Context, period string, periodStart time.Time) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&domain.RankingSnapshotPeriod{}).
+		Where("period = ? AND period_start = ?", period, periodStart.Format("2006-01-02")).
+		Limit(1).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *rankingSnapshotRepository) GetLatestPeriod(ctx context.Context, period string) (*domain.RankingSnapshotPeriod, error) {
+	var latest domain.RankingSnapshotPeriod
+	if err := r.db.WithContext(ctx).
+		Where("period = ?", period).
+		Order("period_start DESC").
+		First(&latest).Error; err != nil {
+		return nil, err
+	}
+	return &latest, nil
+}
+
+func (r *rankingSnapshotRepository) CreateSnapshots(ctx context.Context, period *domain.RankingSnapshotPeriod, snapshots []domain.RankingSnapshot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(snapshots) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				CreateInBatches(snapshots, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(period).Error
+	})
+}
+
+func (r *rankingSnapshotRepository) GetPeriods(ctx context.Context, period string, limit int) ([]domain.RankingSnapshotPeriod, error) {
+	var periods []domain.RankingSnapshotPeriod
+	if err := r.db.WithContext(ctx).
+		Where("period = ?", period).
+		Order("period_start DESC").
+		Limit(limit).
+		Find(&periods).Error; err != nil {
+		return nil, err
+	}
+	return periods, nil
+}
+
+func (r *rankingSnapshotRepository) GetSnapshot(ctx context.Context, period, metric string, periodStart time.Time) ([]domain.UserRanking, error) {
+	var results []domain.UserRanking
+	if err := r.db.WithContext(ctx).
+		Table("ranking_snapshots").
+		Select("ranking_snapshots.user_id, ranking_snapshots.metric, ranking_snapshots.rank, ranking_snapshots.value, ranking_snapshots.total_duration, ranking_snapshots.attendance_days, ranking_snapshots.total_points, users.display_name, users.username, users.profile_visibility").
+		Joins("JOIN users ON users.id = ranking_snapshots.user_id").
+		Where("ranking_snapshots.period = ? AND ranking_snapshots.metric = ? AND ranking_snapshots.period_start = ?",
+			period, metric, periodStart.Format("2006-01-02")).
+		Where("users.is_active = true AND users.is_leaderboard_public = true").
+		Order("ranking_snapshots.rank, ranking_snapshots.user_id").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *rankingSnapshotRepository) GetUserHistory(ctx context.Context, userID uint, period, metric string, limit int) ([]domain.RankingSnapshot, error) {
+	// 同じ期間で値が大きい、現在ランキングに参加しているユーザーの数 + 1（同じ値は同順位）
+	rank := r.db.
+		Table("ranking_snapshots AS other").
+		Select("COUNT(*) + 1").
+		Joins("JOIN users ON users.id = other.user_id").
+		Where("other.period = ranking_snapshots.period AND other.metric = ranking_snapshots.metric AND other.period_start = ranking_snapshots.period_start").
+		Where("other.value > ranking_snapshots.value").
+		Where("users.is_active = true AND users.is_leaderboard_public = true")
+
+	var history []domain.RankingSnapshot
+	if err := r.db.WithContext(ctx).
+		Select("ranking_snapshots.id, ranking_snapshots.period, ranking_snapshots.metric, ranking_snapshots.period_start, ranking_snapshots.period_end, "+
+			"ranking_snapshots.user_id, ranking_snapshots.value, ranking_snapshots.total_duration, ranking_snapshots.attendance_days, "+
+			"ranking_snapshots.total_points, ranking_snapshots.created_at, (?) AS rank", rank).
+		Where("user_id = ? AND period = ? AND metric = ?", userID, period, metric).
+		Order("period_start DESC").
+		Limit(limit).
+		Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (r *rankingSnapshotRepository) GetPeriodTotals(ctx context.Context, from, to time.Time, excludeHolidays bool) ([]domain.UserRanking, error) {
+	fromDate, toDate := from.Format("2006-01-02"), to.Format("2006-01-02")
+	dailies := r.db.
+		Table("daily_attendances").
+		Select("user_id, total_duration_minutes AS total_duration, 1 AS attendance_days, 0 AS total_points").
+		Where("attendance_date >= ? AND attendance_date < ?", fromDate, toDate)
+	if excludeHolidays {
+		dailies = dailies.Where("is_holiday = false")
+	}
+	// 出席ポイントは出席日、それ以外は付与日で期間に含めるか判定する
+	points := r.db.
+		Table("point_ledger").
+		Select("user_id, 0 AS total_duration, 0 AS attendance_days, amount AS total_points").
+		Where("COALESCE(attendance_date, created_at::date) >= ? AND COALESCE(attendance_date, created_at::date) < ?", fromDate, toDate)
+
+	var results []domain.UserRanking
+	if err := r.db.WithContext(ctx).
+		Table("((?) UNION ALL (?)) AS totals", dailies, points).
+		Select("user_id, SUM(total_duration) AS total_duration, SUM(attendance_days) AS attendance_days, SUM(total_points) AS total_points").
+		Group("user_id").
+		Order("user_id").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
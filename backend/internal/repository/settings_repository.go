@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 
 	"github.com/kasa021/watabe-lab-app/internal/domain"
 	"gorm.io/gorm"
@@ -9,6 +12,13 @@ import (
 
 type SettingsRepository interface {
 	GetByKey(ctx context.Context, key string) (*domain.Setting, error)
+	// GetValue は設定値のJSONを dest にデコードする。
+	// auto_checkout_minutes (数値) や holidays (配列) のように
+	// オブジェクト以外の値を持つ設定はこちらで取得する。
+	GetValue(ctx context.Context, key string, dest interface{}) error
+	// SetValue は value をJSONにして設定値を保存する（無ければ作成する）。
+	// updatedBy が 0 の場合（CLIからの更新等）は更新者を記録しない。
+	SetValue(ctx context.Context, key string, value interface{}, updatedBy uint) error
 }
 
 type settingsRepository struct {
@@ -26,3 +36,42 @@ func (r *settingsRepository) GetByKey(ctx context.Context, key string) (*domain.
 	}
 	return &setting, nil
 }
+
+func (r *settingsRepository) GetValue(ctx context.Context, key string, dest interface{}) error {
+	var raw []byte
+	row := r.db.WithContext(ctx).
+		Model(&domain.Setting{}).
+		Select("value").
+		Where("key = ?", key).
+		Row()
+	if err := row.Scan(&raw); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return gorm.ErrRecordNotFound
+		}
+		return err
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+func (r *settingsRepository) SetValue(ctx context.Context, key string, value interface{}, updatedBy uint) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO settings (key, value, updated_at, updated_by)
+		VALUES (?, ?::jsonb, NOW(), ?)
+		ON CONFLICT (key) DO UPDATE SET
+			value = EXCLUDED.value,
+			updated_at = EXCLUDED.updated_at,
+			updated_by = EXCLUDED.updated_by`,
+		key, string(raw), nullableID(updatedBy),
+	).Error
+}
+
+func nullableID(id uint) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
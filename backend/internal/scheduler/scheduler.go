@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job 定期実行ジョブ
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler 登録されたジョブを一定間隔で実行する
+type Scheduler struct {
+	jobs []Job
+}
+
+// NewScheduler スケジューラーを作成
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register ジョブを登録する（Start より前に呼ぶこと）
+func (s *Scheduler) Register(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start 登録済みのジョブをそれぞれ別のgoroutineで起動する。
+// 各ジョブは起動直後に一度実行され、以降は Interval ごとに実行される。
+// ctx がキャンセルされると停止する。
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	s.runOnce(ctx, job)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	// ジョブ内のpanicでサーバー全体が落ちないようにする
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("scheduler: job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		log.Printf("scheduler: job %s failed: %v", job.Name, err)
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+// settingAcademicTerms 学期の定義
+const settingAcademicTerms = "academic_terms"
+
+// AcademicTerm 学期の期間。年度内の開始日と終了日（終了日を含む）を "MM-DD" で指定し、1〜3月は翌年として扱う
+type AcademicTerm struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// defaultAcademicTerms academic_terms 設定が無い場合の学期（前期・後期）
+var defaultAcademicTerms = map[string]AcademicTerm{
+	"spring": {Start: "04-01", End: "09-30"},
+	"fall":   {Start: "10-01", End: "03-31"},
+}
+
+// loadAcademicTerms academic_terms 設定を読み込む
+func loadAcademicTerms(ctx context.Context, settingsRepo repository.SettingsRepository) (map[string]AcademicTerm, error) {
+	var terms map[string]AcademicTerm
+	if err := settingsRepo.GetValue(ctx, settingAcademicTerms, &terms); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaultAcademicTerms, nil
+		}
+		return nil, fmt.Errorf("%s: %w", settingAcademicTerms, err)
+	}
+	return terms, nil
+}
+
+// academicYearOf t を含む年度
+func academicYearOf(t time.Time, loc *time.Location) int {
+	t = t.In(loc)
+	if t.Month() < time.April {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+// termDate 年度 year 内の "MM-DD" の日付
+func termDate(mmdd string, year int, loc *time.Location) (time.Time, error) {
+	d, err := time.Parse("01-02", mmdd)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if d.Month() < time.April {
+		year++
+	}
+	return time.Date(year, d.Month(), d.Day(), 0, 0, 0, 0, loc), nil
+}
+
+// academicTermRange 年度 year の学期 name の範囲 [start, end)
+func academicTermRange(terms map[string]AcademicTerm, name string, year int, loc *time.Location) (time.Time, time.Time, error) {
+	term, ok := terms[name]
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown term %q", name)
+	}
+	start, err := termDate(term.Start, year, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("term %q: start: %w", name, err)
+	}
+	end, err := termDate(term.End, year, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("term %q: end: %w", name, err)
+	}
+	end = end.AddDate(0, 0, 1)
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("term %q: start %s is after end %s", name, term.Start, term.End)
+	}
+	return start, end, nil
+}
+
+// previousAcademicTerm 年度 year の学期 name の直前の学期（年度の最初の学期なら前年度の最後の学期）
+func previousAcademicTerm(terms map[string]AcademicTerm, name string, year int, loc *time.Location) (string, int, error) {
+	type termStart struct {
+		name  string
+		start time.Time
+	}
+	starts := make([]termStart, 0, len(terms))
+	for n, term := range terms {
+		start, err := termDate(term.Start, year, loc)
+		if err != nil {
+			return "", 0, fmt.Errorf("term %q: start: %w", n, err)
+		}
+		starts = append(starts, termStart{name: n, start: start})
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].start.Before(starts[j].start) })
+
+	for i, t := range starts {
+		if t.name != name {
+			continue
+		}
+		if i == 0 {
+			return starts[len(starts)-1].name, year - 1, nil
+		}
+		return starts[i-1].name, year, nil
+	}
+	return "", 0, fmt.Errorf("unknown term %q", name)
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAchievementNotFound  = errors.New("achievement not found")
+	ErrInvalidAchievement   = errors.New("invalid achievement")
+	ErrAchievementCodeTaken = errors.New("achievement code already exists")
+	ErrAchievementUnlocked  = errors.New("achievement has been unlocked by users")
+	ErrInvalidIcon          = errors.New("invalid icon")
+)
+
+// 称号のカテゴリ（achievements.category）
+var achievementCategories = map[string]bool{
+	"attendance": true,
+	"time":       true,
+	"streak":     true,
+	"special":    true,
+}
+
+// maxAchievementIconBytes アイコン画像の最大サイズ
+const maxAchievementIconBytes = 512 << 10
+
+// achievementIconTypes 受け付けるアイコン画像の形式と保存する拡張子。
+// SVG はスクリプトを埋め込めるため受け付けない
+var achievementIconTypes = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// AchievementInput 管理者による称号の作成・更新内容
+type AchievementInput struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// Description 省略時は作成なら空、更新なら変更しない
+	Description *string `json:"description"`
+	// IconURL 外部の画像の URL。省略時は変更しない（アップロードは SetIcon で行う）
+	IconURL        *string      `json:"icon_url"`
+	Category       string       `json:"category"`
+	ConditionType  string       `json:"condition_type"`
+	ConditionValue domain.JSONB `json:"condition_value"`
+	// PointsReward 省略時は作成なら0、更新なら変更しない
+	PointsReward *int `json:"points_reward"`
+	// IsActive 省略時は作成なら有効、更新なら変更しない
+	IsActive *bool `json:"is_active"`
+	// DisplayOrder 省略時は作成なら末尾、更新なら変更しない
+	DisplayOrder *int `json:"display_order"`
+}
+
+// AchievementAdminService 管理者・教員による称号マスタの管理
+type AchievementAdminService interface {
+	// ListAchievements は無効な称号も含めて表示順に返す
+	ListAchievements(ctx context.Context) ([]domain.Achievement, error)
+	CreateAchievement(ctx context.Context, input *AchievementInput) (*domain.Achievement, error)
+	UpdateAchievement(ctx context.Context, id uint, input *AchievementInput) (*domain.Achievement, error)
+	// DeleteAchievement は称号を削除する。獲得したユーザーがいる場合は force を指定しない限り削除しない
+	// （獲得記録も消えるため、通常は is_active を false にする）
+	DeleteAchievement(ctx context.Context, id uint, force bool) error
+	// Reorder は ids（全ての称号の ID）の順に表示順を振り直す
+	Reorder(ctx context.Context, ids []uint) ([]domain.Achievement, error)
+	// SetIcon はアイコン画像を保存し、icon_url をその URL にする
+	SetIcon(ctx context.Context, id uint, r io.Reader) (*domain.Achievement, error)
+}
+
+type achievementAdminService struct {
+	repo repository.AchievementRepository
+	// iconDir アップロードされたアイコンの保存先、iconURL その配信元の URL（末尾は /）
+	iconDir string
+	iconURL string
+}
+
+func NewAchievementAdminService(repo repository.AchievementRepository, iconDir, iconURL string) AchievementAdminService {
+	return &achievementAdminService{
+		repo:    repo,
+		iconDir: iconDir,
+		iconURL: iconURL,
+	}
+}
+
+func (s *achievementAdminService) ListAchievements(ctx context.Context) ([]domain.Achievement, error) {
+	return s.repo.FindAll(ctx)
+}
+
+func (s *achievementAdminService) CreateAchievement(ctx context.Context, input *AchievementInput) (*domain.Achievement, error) {
+	if err := s.validateInput(ctx, 0, input); err != nil {
+		return nil, err
+	}
+
+	ach := &domain.Achievement{IsActive: true}
+	if input.DisplayOrder == nil {
+		all, err := s.repo.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range all {
+			if a.DisplayOrder >= ach.DisplayOrder {
+				ach.DisplayOrder = a.DisplayOrder + 1
+			}
+		}
+	}
+	applyAchievementInput(ach, input)
+
+	if err := s.repo.Create(ctx, ach); err != nil {
+		return nil, err
+	}
+	return ach, nil
+}
+
+func (s *achievementAdminService) UpdateAchievement(ctx context.Context, id uint, input *AchievementInput) (*domain.Achievement, error) {
+	ach, err := s.findAchievement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateInput(ctx, id, input); err != nil {
+		return nil, err
+	}
+
+	oldIcon := ach.IconURL
+	applyAchievementInput(ach, input)
+	if err := s.repo.Update(ctx, ach); err != nil {
+		return nil, err
+	}
+	if ach.IconURL != oldIcon {
+		s.removeIcon(oldIcon)
+	}
+	return ach, nil
+}
+
+func (s *achievementAdminService) DeleteAchievement(ctx context.Context, id uint, force bool) error {
+	ach, err := s.findAchievement(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !force {
+		count, err := s.repo.CountUnlocked(ctx, id)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%d user(s): %w", count, ErrAchievementUnlocked)
+		}
+	}
+
+	// 獲得記録は一緒に削除される。報酬のポイントは残す
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.removeIcon(ach.IconURL)
+	return nil
+}
+
+func (s *achievementAdminService) Reorder(ctx context.Context, ids []uint) ([]domain.Achievement, error) {
+	all, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make(map[uint]bool, len(all))
+	for _, a := range all {
+		remaining[a.ID] = true
+	}
+	for _, id := range ids {
+		if !remaining[id] {
+			return nil, fmt.Errorf("unknown or duplicate id %d: %w", id, ErrInvalidAchievement)
+		}
+		delete(remaining, id)
+	}
+	if len(remaining) > 0 {
+		return nil, fmt.Errorf("ids must list all %d achievements: %w", len(all), ErrInvalidAchievement)
+	}
+
+	if err := s.repo.Reorder(ctx, ids); err != nil {
+		return nil, err
+	}
+	return s.repo.FindAll(ctx)
+}
+
+func (s *achievementAdminService) SetIcon(ctx context.Context, id uint, r io.Reader) (*domain.Achievement, error) {
+	ach, err := s.findAchievement(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxAchievementIconBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAchievementIconBytes {
+		return nil, fmt.Errorf("larger than %d bytes: %w", maxAchievementIconBytes, ErrInvalidIcon)
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := achievementIconTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported type %s: %w", contentType, ErrInvalidIcon)
+	}
+
+	if err := os.MkdirAll(s.iconDir, 0o755); err != nil {
+		return nil, err
+	}
+	// 差し替えたときにブラウザのキャッシュが使われないよう、毎回別の名前にする
+	name := fmt.Sprintf("%d-%d%s", ach.ID, time.Now().UnixNano(), ext)
+	path := filepath.Join(s.iconDir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, err
+	}
+
+	oldIcon := ach.IconURL
+	ach.IconURL = s.iconURL + name
+	if err := s.repo.Update(ctx, ach); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	s.removeIcon(oldIcon)
+	return ach, nil
+}
+
+func (s *achievementAdminService) findAchievement(ctx context.Context, id uint) (*domain.Achievement, error) {
+	ach, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAchievementNotFound
+		}
+		return nil, err
+	}
+	return ach, nil
+}
+
+// validateInput 入力値と condition_value のスキーマを検証する。id は更新対象（作成の場合は 0）
+func (s *achievementAdminService) validateInput(ctx context.Context, id uint, input *AchievementInput) error {
+	input.Code = strings.TrimSpace(input.Code)
+	input.Name = strings.TrimSpace(input.Name)
+	switch {
+	case input.Code == "":
+		return fmt.Errorf("code is required: %w", ErrInvalidAchievement)
+	case input.Name == "":
+		return fmt.Errorf("name is required: %w", ErrInvalidAchievement)
+	case !achievementCategories[input.Category]:
+		return fmt.Errorf("unknown category %q: %w", input.Category, ErrInvalidAchievement)
+	case input.PointsReward != nil && *input.PointsReward < 0:
+		return fmt.Errorf("points_reward must not be negative: %w", ErrInvalidAchievement)
+	}
+	if input.ConditionValue == nil {
+		input.ConditionValue = domain.JSONB{}
+	}
+	if err := ValidateCondition(input.ConditionType, input.ConditionValue); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.FindByCode(ctx, input.Code)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && existing.ID != id {
+		return fmt.Errorf("%s: %w", input.Code, ErrAchievementCodeTaken)
+	}
+	return nil
+}
+
+// removeIcon アップロードされたアイコンのファイルを削除する（外部の URL の場合は何もしない）
+func (s *achievementAdminService) removeIcon(iconURL string) {
+	if iconURL == "" || !strings.HasPrefix(iconURL, s.iconURL) {
+		return
+	}
+	name := filepath.Base(strings.TrimPrefix(iconURL, s.iconURL))
+	if err := os.Remove(filepath.Join(s.iconDir, name)); err != nil && !os.IsNotExist(err) {
+		log.Printf("failed to remove achievement icon %s: %v", name, err)
+	}
+}
+
+func applyAchievementInput(ach *domain.Achievement, input *AchievementInput) {
+	ach.Code = input.Code
+	ach.Name = input.Name
+	if input.Description != nil {
+		ach.Description = *input.Description
+	}
+	if input.IconURL != nil {
+		ach.IconURL = *input.IconURL
+	}
+	ach.Category = input.Category
+	ach.ConditionType = input.ConditionType
+	ach.ConditionValue = input.ConditionValue
+	if input.PointsReward != nil {
+		ach.PointsReward = *input.PointsReward
+	}
+	if input.IsActive != nil {
+		ach.IsActive = *input.IsActive
+	}
+	if input.DisplayOrder != nil {
+		ach.DisplayOrder = *input.DisplayOrder
+	}
+}
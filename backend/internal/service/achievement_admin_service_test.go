@@ -0,0 +1,280 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeAchievementMaster 称号マスタをメモリ上に持つ AchievementRepository（管理で使うメソッドのみ）
+type fakeAchievementMaster struct {
+	repository.AchievementRepository
+	achievements map[uint]domain.Achievement
+	// unlocked 称号ごとの獲得したユーザー数
+	unlocked map[uint]int64
+}
+
+func (r *fakeAchievementMaster) FindAll(ctx context.Context) ([]domain.Achievement, error) {
+	all := make([]domain.Achievement, 0, len(r.achievements))
+	for _, a := range r.achievements {
+		all = append(all, a)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].DisplayOrder != all[j].DisplayOrder {
+			return all[i].DisplayOrder < all[j].DisplayOrder
+		}
+		return all[i].ID < all[j].ID
+	})
+	return all, nil
+}
+
+func (r *fakeAchievementMaster) FindByID(ctx context.Context, id uint) (*domain.Achievement, error) {
+	ach, ok := r.achievements[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &ach, nil
+}
+
+func (r *fakeAchievementMaster) FindByCode(ctx context.Context, code string) (*domain.Achievement, error) {
+	for _, a := range r.achievements {
+		if a.Code == code {
+			return &a, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAchievementMaster) Create(ctx context.Context, ach *domain.Achievement) error {
+	ach.ID = uint(len(r.achievements) + 1)
+	r.achievements[ach.ID] = *ach
+	return nil
+}
+
+func (r *fakeAchievementMaster) Update(ctx context.Context, ach *domain.Achievement) error {
+	r.achievements[ach.ID] = *ach
+	return nil
+}
+
+func (r *fakeAchievementMaster) Delete(ctx context.Context, id uint) error {
+	delete(r.achievements, id)
+	return nil
+}
+
+func (r *fakeAchievementMaster) CountUnlocked(ctx context.Context, achievementID uint) (int64, error) {
+	return r.unlocked[achievementID], nil
+}
+
+func (r *fakeAchievementMaster) Reorder(ctx context.Context, ids []uint) error {
+	for i, id := range ids {
+		ach := r.achievements[id]
+		ach.DisplayOrder = i + 1
+		r.achievements[id] = ach
+	}
+	return nil
+}
+
+const testIconURL = "/uploads/achievements/"
+
+func newAchievementAdminFixture(t *testing.T) (AchievementAdminService, *fakeAchievementMaster, string) {
+	repo := &fakeAchievementMaster{
+		achievements: map[uint]domain.Achievement{
+			1: {ID: 1, Code: "first", Name: "はじめて", Description: "初めてのチェックイン", Category: "special",
+				ConditionType: ConditionFirstTime, ConditionValue: domain.JSONB{}, PointsReward: 10, IsActive: true, DisplayOrder: 1},
+			2: {ID: 2, Code: "streak5", Name: "5日連続", Category: "streak",
+				ConditionType: ConditionStreakDays, ConditionValue: domain.JSONB{"days": float64(5)}, IsActive: true, DisplayOrder: 2},
+		},
+		unlocked: map[uint]int64{1: 3},
+	}
+	iconDir := t.TempDir()
+	return NewAchievementAdminService(repo, iconDir, testIconURL), repo, iconDir
+}
+
+func intPtr(v int) *int { return &v }
+
+func stringPtr(v string) *string { return &v }
+
+func TestAchievementAdminService_Create(t *testing.T) {
+	s, repo, _ := newAchievementAdminFixture(t)
+
+	ach, err := s.CreateAchievement(context.Background(), &AchievementInput{
+		Code: " late3 ", Name: "夜更かし", Category: "time",
+		ConditionType: ConditionLateCheckIn, ConditionValue: domain.JSONB{"count": float64(3), "time": "21:00:00"},
+		PointsReward: intPtr(5),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 表示順は末尾、省略した is_active は有効
+	if ach.Code != "late3" || ach.DisplayOrder != 3 || !ach.IsActive || ach.PointsReward != 5 || repo.achievements[ach.ID].Name != "夜更かし" {
+		t.Errorf("created = %+v", ach)
+	}
+}
+
+func TestAchievementAdminService_CreateRejected(t *testing.T) {
+	valid := func() *AchievementInput {
+		return &AchievementInput{Code: "days10", Name: "10日", Category: "attendance",
+			ConditionType: ConditionTotalDays, ConditionValue: domain.JSONB{"days": float64(10)}}
+	}
+	tests := []struct {
+		name   string
+		modify func(in *AchievementInput)
+		want   error
+	}{
+		{"code が空", func(in *AchievementInput) { in.Code = " " }, ErrInvalidAchievement},
+		{"name が空", func(in *AchievementInput) { in.Name = "" }, ErrInvalidAchievement},
+		{"不明なカテゴリ", func(in *AchievementInput) { in.Category = "misc" }, ErrInvalidAchievement},
+		{"報酬が負", func(in *AchievementInput) { in.PointsReward = intPtr(-1) }, ErrInvalidAchievement},
+		{"不明な条件タイプ", func(in *AchievementInput) { in.ConditionType = "moon_phase" }, ErrUnknownConditionType},
+		{"条件値が無い", func(in *AchievementInput) { in.ConditionValue = nil }, ErrInvalidConditionValue},
+		{"条件値が整数でない", func(in *AchievementInput) { in.ConditionValue = domain.JSONB{"days": 1.5} }, ErrInvalidConditionValue},
+		{"条件値が0", func(in *AchievementInput) { in.ConditionValue = domain.JSONB{"days": float64(0)} }, ErrInvalidConditionValue},
+		{"条件値が文字列", func(in *AchievementInput) { in.ConditionValue = domain.JSONB{"days": "10"} }, ErrInvalidConditionValue},
+		{"時刻の形式が不正", func(in *AchievementInput) {
+			in.ConditionType = ConditionEarlyCheckIn
+			in.ConditionValue = domain.JSONB{"days": float64(3), "time": "8am"}
+		}, ErrInvalidConditionValue},
+		{"code が重複", func(in *AchievementInput) { in.Code = "first" }, ErrAchievementCodeTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, _ := newAchievementAdminFixture(t)
+			input := valid()
+			tt.modify(input)
+			if _, err := s.CreateAchievement(context.Background(), input); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if len(repo.achievements) != 2 {
+				t.Errorf("achievement was created: %+v", repo.achievements)
+			}
+		})
+	}
+}
+
+func TestAchievementAdminService_UpdateKeepsOmittedFields(t *testing.T) {
+	s, repo, _ := newAchievementAdminFixture(t)
+	input := func() *AchievementInput {
+		return &AchievementInput{Code: "first", Name: "はじめの一歩", Category: "special", ConditionType: ConditionFirstTime}
+	}
+
+	// description・points_reward などを省略した場合は元の値のまま
+	ach, err := s.UpdateAchievement(context.Background(), 1, input())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ach.Name != "はじめの一歩" || ach.Description != "初めてのチェックイン" || ach.PointsReward != 10 || !ach.IsActive || ach.DisplayOrder != 1 {
+		t.Errorf("updated = %+v", ach)
+	}
+
+	// 明示した空文字・0 は反映する
+	cleared := input()
+	cleared.Description, cleared.PointsReward = stringPtr(""), intPtr(0)
+	if _, err := s.UpdateAchievement(context.Background(), 1, cleared); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.achievements[1]; got.Description != "" || got.PointsReward != 0 {
+		t.Errorf("cleared = %+v", got)
+	}
+
+	if _, err := s.UpdateAchievement(context.Background(), 9, input()); !errors.Is(err, ErrAchievementNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrAchievementNotFound)
+	}
+	taken := input()
+	taken.Code = "streak5"
+	if _, err := s.UpdateAchievement(context.Background(), 1, taken); !errors.Is(err, ErrAchievementCodeTaken) {
+		t.Errorf("err = %v, want %v", err, ErrAchievementCodeTaken)
+	}
+}
+
+func TestAchievementAdminService_Delete(t *testing.T) {
+	s, repo, _ := newAchievementAdminFixture(t)
+
+	// 獲得したユーザーがいる場合は force が必要
+	if err := s.DeleteAchievement(context.Background(), 1, false); !errors.Is(err, ErrAchievementUnlocked) {
+		t.Fatalf("err = %v, want %v", err, ErrAchievementUnlocked)
+	}
+	if err := s.DeleteAchievement(context.Background(), 1, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteAchievement(context.Background(), 2, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.achievements) != 0 {
+		t.Errorf("achievements = %+v", repo.achievements)
+	}
+	if err := s.DeleteAchievement(context.Background(), 1, true); !errors.Is(err, ErrAchievementNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrAchievementNotFound)
+	}
+}
+
+func TestAchievementAdminService_Reorder(t *testing.T) {
+	for _, ids := range [][]uint{{2}, {2, 2}, {2, 1, 3}, {}} {
+		s, _, _ := newAchievementAdminFixture(t)
+		if _, err := s.Reorder(context.Background(), ids); !errors.Is(err, ErrInvalidAchievement) {
+			t.Errorf("ids %v: err = %v, want %v", ids, err, ErrInvalidAchievement)
+		}
+	}
+
+	s, _, _ := newAchievementAdminFixture(t)
+	ordered, err := s.Reorder(context.Background(), []uint{2, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ordered) != 2 || ordered[0].ID != 2 || ordered[1].ID != 1 {
+		t.Errorf("ordered = %+v", ordered)
+	}
+}
+
+func TestAchievementAdminService_SetIcon(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"SVG", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)},
+		{"テキスト", []byte("not an image")},
+		{"大きすぎる", append(append([]byte(nil), png...), make([]byte, maxAchievementIconBytes)...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, iconDir := newAchievementAdminFixture(t)
+			if _, err := s.SetIcon(context.Background(), 1, bytes.NewReader(tt.data)); !errors.Is(err, ErrInvalidIcon) {
+				t.Errorf("err = %v, want %v", err, ErrInvalidIcon)
+			}
+			if files, _ := os.ReadDir(iconDir); len(files) != 0 {
+				t.Errorf("files were saved: %v", files)
+			}
+		})
+	}
+
+	s, repo, iconDir := newAchievementAdminFixture(t)
+	first, err := s.SetIcon(context.Background(), 1, bytes.NewReader(png))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(first.IconURL, testIconURL) || !strings.HasSuffix(first.IconURL, ".png") || repo.achievements[1].IconURL != first.IconURL {
+		t.Fatalf("icon_url = %q", first.IconURL)
+	}
+	// 差し替えた場合は前のファイルを削除する
+	second, err := s.SetIcon(context.Background(), 1, bytes.NewReader(png))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := os.ReadDir(iconDir)
+	if len(files) != 1 || files[0].Name() != filepath.Base(second.IconURL) {
+		t.Errorf("files = %v, want only %s", files, filepath.Base(second.IconURL))
+	}
+
+	if _, err := s.SetIcon(context.Background(), 9, bytes.NewReader(png)); !errors.Is(err, ErrAchievementNotFound) {
+		t.Errorf("err = %v, want %v", err, ErrAchievementNotFound)
+	}
+}
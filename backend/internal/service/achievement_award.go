@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+// awardLogLimit 手動付与の履歴の取得件数の上限
+const awardLogLimit = 200
+
+func (s *achievementService) Award(ctx context.Context, actorID, achievementID, userID uint, note string) (*domain.UserAchievement, error) {
+	ach, user, note, err := s.manualAward(ctx, achievementID, userID, note)
+	if err != nil {
+		return nil, err
+	}
+	// 無効にした称号は一覧や進捗に出ないため、新たには付与しない（取り消しはできる）
+	if !ach.IsActive {
+		return nil, ErrAchievementInactive
+	}
+	unlocked, err := s.repo.HasUnlocked(ctx, userID, achievementID)
+	if err != nil {
+		return nil, err
+	}
+	if unlocked {
+		return nil, ErrAchievementAwarded
+	}
+
+	ua := &domain.UserAchievement{
+		UserID:        userID,
+		AchievementID: achievementID,
+		AchievedAt:    time.Now(),
+		AwardedBy:     &actorID,
+		Note:          note,
+	}
+	// 獲得記録・報酬のポイント・履歴のどれかだけが残らないよう、まとめて記録する
+	err = s.repo.Transaction(ctx, func(repo repository.AchievementRepository, pointRepo repository.PointRepository) error {
+		if err := unlockAchievement(ctx, repo, pointRepo, ua, *ach); err != nil {
+			return err
+		}
+		return repo.CreateAwardLog(ctx, &domain.AchievementAwardLog{
+			UserID:        userID,
+			AchievementID: achievementID,
+			ActorID:       &actorID,
+			Action:        domain.AchievementAwardActionAward,
+			Note:          note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.hub != nil {
+		notifyAchievementsUnlocked(s.hub, *user, []domain.Achievement{*ach}, true)
+	}
+	ua.Achievement = *ach
+	return ua, nil
+}
+
+func (s *achievementService) Revoke(ctx context.Context, actorID, achievementID, userID uint, note string) error {
+	_, _, note, err := s.manualAward(ctx, achievementID, userID, note)
+	if err != nil {
+		return err
+	}
+	unlocked, err := s.repo.HasUnlocked(ctx, userID, achievementID)
+	if err != nil {
+		return err
+	}
+	if !unlocked {
+		return ErrAchievementNotAwarded
+	}
+
+	return s.repo.Transaction(ctx, func(repo repository.AchievementRepository, pointRepo repository.PointRepository) error {
+		if err := repo.DeleteUserAchievement(ctx, userID, achievementID); err != nil {
+			return err
+		}
+		return repo.CreateAwardLog(ctx, &domain.AchievementAwardLog{
+			UserID:        userID,
+			AchievementID: achievementID,
+			ActorID:       &actorID,
+			Action:        domain.AchievementAwardActionRevoke,
+			Note:          note,
+		})
+	})
+}
+
+func (s *achievementService) GetAwardLogs(ctx context.Context, userID uint) ([]domain.AchievementAwardLog, error) {
+	return s.repo.GetAwardLogs(ctx, userID, awardLogLimit)
+}
+
+// manualAward 手動付与・取り消しの対象を確認し、称号・ユーザー・前後の空白を除いたメモを返す
+func (s *achievementService) manualAward(ctx context.Context, achievementID, userID uint, note string) (*domain.Achievement, *domain.User, string, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, nil, "", ErrAwardNoteRequired
+	}
+
+	ach, err := s.repo.FindByID(ctx, achievementID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, "", ErrAchievementNotFound
+		}
+		return nil, nil, "", err
+	}
+	if ach.ConditionType != ConditionManual {
+		return nil, nil, "", fmt.Errorf("%s: %w", ach.Code, ErrNotManualAchievement)
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, "", ErrUserNotFound
+		}
+		return nil, nil, "", err
+	}
+	return ach, user, note, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+// awardRecords 手動付与で記録されるデータ
+type awardRecords struct {
+	owned  []domain.UserAchievement
+	points []domain.PointTransaction
+	logs   []domain.AchievementAwardLog
+}
+
+// fakeAwardRepo 手動付与で使うメソッドだけを持つ AchievementRepository と PointRepository。
+// Transaction は fn がエラーを返すと記録を元に戻す
+type fakeAwardRepo struct {
+	repository.AchievementRepository
+	repository.PointRepository
+	achievements map[uint]domain.Achievement
+	records      awardRecords
+	// failAwardLog CreateAwardLog を失敗させる
+	failAwardLog bool
+}
+
+func (r *fakeAwardRepo) Transaction(ctx context.Context, fn func(repo repository.AchievementRepository, pointRepo repository.PointRepository) error) error {
+	saved := awardRecords{
+		owned:  append([]domain.UserAchievement(nil), r.records.owned...),
+		points: append([]domain.PointTransaction(nil), r.records.points...),
+		logs:   append([]domain.AchievementAwardLog(nil), r.records.logs...),
+	}
+	if err := fn(r, r); err != nil {
+		r.records = saved
+		return err
+	}
+	return nil
+}
+
+func (r *fakeAwardRepo) FindByID(ctx context.Context, id uint) (*domain.Achievement, error) {
+	ach, ok := r.achievements[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &ach, nil
+}
+
+func (r *fakeAwardRepo) HasUnlocked(ctx context.Context, userID uint, achievementID uint) (bool, error) {
+	for _, ua := range r.records.owned {
+		if ua.UserID == userID && ua.AchievementID == achievementID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeAwardRepo) CreateUserAchievement(ctx context.Context, ua *domain.UserAchievement) error {
+	r.records.owned = append(r.records.owned, *ua)
+	return nil
+}
+
+func (r *fakeAwardRepo) CreateTransaction(ctx context.Context, tx *domain.PointTransaction) error {
+	r.records.points = append(r.records.points, *tx)
+	return nil
+}
+
+func (r *fakeAwardRepo) CreateAwardLog(ctx context.Context, log *domain.AchievementAwardLog) error {
+	if r.failAwardLog {
+		return errors.New("insert failed")
+	}
+	r.records.logs = append(r.records.logs, *log)
+	return nil
+}
+
+func newAwardFixture() (AchievementService, *fakeAwardRepo) {
+	repo := &fakeAwardRepo{achievements: map[uint]domain.Achievement{
+		1: {ID: 1, Code: "helper", Name: "お手伝い", ConditionType: ConditionManual, PointsReward: 50, IsActive: true},
+		2: {ID: 2, Code: "retired", Name: "廃止", ConditionType: ConditionManual, IsActive: false},
+	}}
+	users := fakeUserRepo{users: map[uint]domain.User{1: {ID: 1, Username: "alice"}}}
+	return NewAchievementService(repo, users, nil, nil, repo, nil, nil), repo
+}
+
+func TestAchievementService_Award(t *testing.T) {
+	s, repo := newAwardFixture()
+
+	if _, err := s.Award(context.Background(), 9, 2, 1, "r"); !errors.Is(err, ErrAchievementInactive) {
+		t.Fatalf("err = %v, want %v", err, ErrAchievementInactive)
+	}
+
+	ua, err := s.Award(context.Background(), 9, 1, 1, " 学会の準備 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ua.AwardedBy == nil || *ua.AwardedBy != 9 || ua.Note != "学会の準備" {
+		t.Errorf("ua = %+v", ua)
+	}
+	got := repo.records
+	if len(got.owned) != 1 || len(got.points) != 1 || got.points[0].Amount != 50 || len(got.logs) != 1 || got.logs[0].Action != domain.AchievementAwardActionAward {
+		t.Errorf("records = %+v", got)
+	}
+
+	if _, err := s.Award(context.Background(), 9, 1, 1, "r"); !errors.Is(err, ErrAchievementAwarded) {
+		t.Errorf("err = %v, want %v", err, ErrAchievementAwarded)
+	}
+}
+
+func TestAchievementService_AwardRollsBack(t *testing.T) {
+	s, repo := newAwardFixture()
+	repo.failAwardLog = true
+
+	if _, err := s.Award(context.Background(), 9, 1, 1, "r"); err == nil {
+		t.Fatal("Award succeeded without an award log")
+	}
+	// 履歴を記録できなかった場合は称号もポイントも残さない
+	if got := repo.records; len(got.owned) != 0 || len(got.points) != 0 {
+		t.Errorf("records = %+v", got)
+	}
+}
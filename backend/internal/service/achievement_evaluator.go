@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+var (
+	ErrUnknownConditionType  = errors.New("unknown achievement condition type")
+	ErrInvalidConditionValue = errors.New("invalid achievement condition value")
+)
+
+// 称号の条件タイプ（achievements.condition_type）
+const (
+	ConditionEarlyCheckIn   = "early_check_in"   // {"days": N, "time": "HH:MM:SS"} N日連続で指定時刻前に来る
+	ConditionLateCheckIn    = "late_check_in"    // {"count": N, "time": "HH:MM:SS"} 指定時刻以降のチェックインがN回
+	ConditionStreakDays     = "streak_days"      // {"days": N} N日連続で来る
+	ConditionTotalHours     = "total_hours"      // {"hours": N} 累計滞在時間N時間
+	ConditionTotalDays      = "total_days"       // {"days": N} 累計出席日数N日
+	ConditionFirstTime      = "first_time"       // {} 初めてのチェックイン
+	ConditionWeekendCheckIn = "weekend_check_in" // {"count": N} 土日のチェックインがN回
+	ConditionManual         = "manual"           // {} 教員・管理者が手動で付与する（出席履歴からは解除しない）
+)
+
+// conditionKind condition_value の値の種類
+type conditionKind int
+
+const (
+	conditionKindCount     conditionKind = iota // 1以上の整数
+	conditionKindTimeOfDay                      // "HH:MM[:SS]"
+)
+
+// conditionSchemas 条件タイプごとの condition_value のキー（全て必須）
+var conditionSchemas = map[string]map[string]conditionKind{
+	ConditionEarlyCheckIn:   {"days": conditionKindCount, "time": conditionKindTimeOfDay},
+	ConditionLateCheckIn:    {"count": conditionKindCount, "time": conditionKindTimeOfDay},
+	ConditionStreakDays:     {"days": conditionKindCount},
+	ConditionTotalHours:     {"hours": conditionKindCount},
+	ConditionTotalDays:      {"days": conditionKindCount},
+	ConditionFirstTime:      {},
+	ConditionWeekendCheckIn: {"count": conditionKindCount},
+	ConditionManual:         {},
+}
+
+// ValidateCondition condition_value が条件タイプのスキーマに合っているか（不足・余分なキーや不正な値が無いか）
+func ValidateCondition(conditionType string, value domain.JSONB) error {
+	schema, ok := conditionSchemas[conditionType]
+	if !ok {
+		return fmt.Errorf("%s: %w", conditionType, ErrUnknownConditionType)
+	}
+	for key := range value {
+		if _, ok := schema[key]; !ok {
+			return fmt.Errorf("%s: unexpected key %q: %w", conditionType, key, ErrInvalidConditionValue)
+		}
+	}
+	ach := domain.Achievement{Code: conditionType, ConditionValue: value}
+	for key, kind := range schema {
+		var err error
+		switch kind {
+		case conditionKindCount:
+			if v, ok := value[key].(float64); ok && v != math.Trunc(v) {
+				err = fmt.Errorf("%s.%s: not an integer: %w", conditionType, key, ErrInvalidConditionValue)
+			} else {
+				_, err = conditionInt(ach, key)
+			}
+		case conditionKindTimeOfDay:
+			_, err = conditionTimeOfDay(ach, key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AchievementProgress 称号条件に対する現在値と目標値
+type AchievementProgress struct {
+	Current int `json:"current"`
+	Target  int `json:"target"`
+}
+
+// Achieved 目標値に達しているか
+func (p AchievementProgress) Achieved() bool {
+	return p.Current >= p.Target
+}
+
+// Percent 目標値に対する割合（0〜100、切り捨て）
+func (p AchievementProgress) Percent() int {
+	if p.Target <= 0 {
+		return 0
+	}
+	if p.Current >= p.Target {
+		return 100
+	}
+	return p.Current * 100 / p.Target
+}
+
+// achievementEvaluator ユーザーの出席履歴から称号条件の達成状況を計算する
+type achievementEvaluator struct {
+	loc  *time.Location
+	logs []domain.CheckInLog
+	// dailies 日次集計（日付の昇順）。日をまたぐ滞在は日次集計と同じく各日に按分される
+	dailies []domain.DailyAttendance
+	// isRestDay 連続日数の判定で、来なくても連続が途切れない日か
+	isRestDay func(time.Time) bool
+}
+
+// newAchievementEvaluator チェックアウト済みのセッション一覧から評価器を作成する
+func newAchievementEvaluator(logs []domain.CheckInLog, loc *time.Location, holidays holidayCalendar, rules HolidayRules) *achievementEvaluator {
+	e := &achievementEvaluator{
+		loc:       loc,
+		isRestDay: holidays.isRestDay(rules),
+	}
+	for _, l := range logs {
+		if l.CheckOutAt != nil {
+			e.logs = append(e.logs, l)
+		}
+	}
+	e.dailies = buildDailyAttendances(e.logs, loc, holidays)
+	return e
+}
+
+// days 出席日（昇順）
+func (e *achievementEvaluator) days() []time.Time {
+	days := make([]time.Time, len(e.dailies))
+	for i, d := range e.dailies {
+		days[i] = d.AttendanceDate
+	}
+	return days
+}
+
+// Evaluate 称号の条件に対する進捗を計算する
+func (e *achievementEvaluator) Evaluate(ach domain.Achievement) (AchievementProgress, error) {
+	switch ach.ConditionType {
+	case ConditionManual:
+		return AchievementProgress{Current: 0, Target: 1}, nil
+
+	case ConditionFirstTime:
+		current := 0
+		if len(e.logs) > 0 {
+			current = 1
+		}
+		return AchievementProgress{Current: current, Target: 1}, nil
+
+	case ConditionTotalDays:
+		target, err := conditionInt(ach, "days")
+		if err != nil {
+			return AchievementProgress{}, err
+		}
+		return AchievementProgress{Current: len(e.dailies), Target: target}, nil
+
+	case ConditionTotalHours:
+		target, err := conditionInt(ach, "hours")
+		if err != nil {
+			return AchievementProgress{}, err
+		}
+		return AchievementProgress{Current: e.totalMinutes() / 60, Target: target}, nil
+
+	case ConditionStreakDays:
+		target, err := conditionInt(ach, "days")
+		if err != nil {
+			return AchievementProgress{}, err
+		}
+		return AchievementProgress{Current: longestStreak(e.days(), e.isRestDay), Target: target}, nil
+
+	case ConditionEarlyCheckIn:
+		target, err := conditionInt(ach, "days")
+		if err != nil {
+			return AchievementProgress{}, err
+		}
+		before, err := conditionTimeOfDay(ach, "time")
+		if err != nil {
+			return AchievementProgress{}, err
+		}
+		// その日の最初のチェックインが指定時刻より前だった日（日をまたいだ滞在の続きだけの日は含まない）
+		var earlyDays []time.Time
+		for _, d := range e.dailies {
+			if d.FirstCheckInAt != nil && timeOfDay(d.FirstCheckInAt.In(e.loc)) < before {
+				earlyDays = append(earlyDays, d.AttendanceDate)
+			}
+		}
+		return AchievementProgress{Current: longestStreak(earlyDays, e.isRestDay), Target: target}, nil
+
+	case ConditionLateCheckIn:
+		target, err := conditionInt(ach, "count")
+		if err != nil {
+			return AchievementProgress{}, err
+		}
+		after, err := conditionTimeOfDay(ach, "time")
+		if err != nil {
+			return AchievementProgress{}, err
+		}
+		count := 0
+		for _, l := range e.logs {
+			if timeOfDay(l.CheckInAt.In(e.loc)) >= after {
+				count++
+			}
+		}
+		return AchievementProgress{Current: count, Target: target}, nil
+
+	case ConditionWeekendCheckIn:
+		target, err := conditionInt(ach, "count")
+		if err != nil {
+			return AchievementProgress{}, err
+		}
+		count := 0
+		for _, l := range e.logs {
+			switch l.CheckInAt.In(e.loc).Weekday() {
+			case time.Saturday, time.Sunday:
+				count++
+			}
+		}
+		return AchievementProgress{Current: count, Target: target}, nil
+	}
+
+	return AchievementProgress{}, fmt.Errorf("%s: %w", ach.ConditionType, ErrUnknownConditionType)
+}
+
+func (e *achievementEvaluator) totalMinutes() int {
+	total := 0
+	for _, d := range e.dailies {
+		total += d.TotalDurationMinutes
+	}
+	return total
+}
+
+// longestStreak 昇順に並んだ日付の中で、暦日が連続している最長の日数。
+// 間に isRestDay の日（休日・週末）しか無い場合も連続とみなす。
+func longestStreak(days []time.Time, isRestDay func(time.Time) bool) int {
+	longest, current := 0, 0
+	for i, day := range days {
+		if i > 0 && isNextAttendanceDay(days[i-1], day, isRestDay) {
+			current++
+		} else {
+			current = 1
+		}
+		if current > longest {
+			longest = current
+		}
+	}
+	return longest
+}
+
+// isNextAttendanceDay a と b（どちらも 0:00）の間に休みの日しか無いか
+// （DSTで1日が24時間でない場合も考慮して暦日で進める）
+func isNextAttendanceDay(a, b time.Time, isRestDay func(time.Time) bool) bool {
+	if !a.Before(b) {
+		return false
+	}
+	for d := a.AddDate(0, 0, 1); d.Before(b); d = d.AddDate(0, 0, 1) {
+		if !isRestDay(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// timeOfDay 0:00 からの経過秒数
+func timeOfDay(t time.Time) int {
+	return t.Hour()*3600 + t.Minute()*60 + t.Second()
+}
+
+// conditionInt condition_value から整数値を取り出す
+func conditionInt(ach domain.Achievement, key string) (int, error) {
+	v, ok := ach.ConditionValue[key].(float64) // JSONの数値はfloat64で来る
+	if !ok || v <= 0 {
+		return 0, fmt.Errorf("%s.%s: %w", ach.Code, key, ErrInvalidConditionValue)
+	}
+	return int(v), nil
+}
+
+// conditionTimeOfDay condition_value から "HH:MM[:SS]" 形式の時刻を取り出し、0:00 からの経過秒数で返す
+func conditionTimeOfDay(ach domain.Achievement, key string) (int, error) {
+	s, ok := ach.ConditionValue[key].(string)
+	if !ok {
+		return 0, fmt.Errorf("%s.%s: %w", ach.Code, key, ErrInvalidConditionValue)
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return timeOfDay(t), nil
+		}
+	}
+	return 0, fmt.Errorf("%s.%s: %w", ach.Code, key, ErrInvalidConditionValue)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+func TestValidateCondition(t *testing.T) {
+	tests := []struct {
+		name          string
+		conditionType string
+		value         domain.JSONB
+		wantErr       error
+	}{
+		{"正しい値", ConditionEarlyCheckIn, domain.JSONB{"days": float64(5), "time": "10:00"}, nil},
+		{"値なし", ConditionFirstTime, domain.JSONB{}, nil},
+		{"手動付与", ConditionManual, domain.JSONB{}, nil},
+		{"手動付与に値", ConditionManual, domain.JSONB{"days": float64(1)}, ErrInvalidConditionValue},
+		{"キーが足りない", ConditionEarlyCheckIn, domain.JSONB{"days": float64(5)}, ErrInvalidConditionValue},
+		{"余分なキー", ConditionStreakDays, domain.JSONB{"days": float64(5), "hours": float64(1)}, ErrInvalidConditionValue},
+		{"整数でない", ConditionTotalDays, domain.JSONB{"days": 2.5}, ErrInvalidConditionValue},
+		{"0以下", ConditionTotalHours, domain.JSONB{"hours": float64(0)}, ErrInvalidConditionValue},
+		{"時刻の形式", ConditionLateCheckIn, domain.JSONB{"count": float64(3), "time": "22時"}, ErrInvalidConditionValue},
+		{"未対応の条件タイプ", "unknown", domain.JSONB{}, ErrUnknownConditionType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCondition(tt.conditionType, tt.value)
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAchievementEvaluator_Evaluate(t *testing.T) {
+	at := func(day, hour, min int) time.Time { return time.Date(2026, 5, day, hour, min, 0, 0, time.UTC) }
+	// 5/1(金)〜5/5(火)。5/4 は来ていない
+	logs := []domain.CheckInLog{
+		closedLog(1, at(1, 8, 30), at(1, 12, 30)), // 4時間
+		closedLog(1, at(2, 9, 0), at(2, 11, 0)),   // 土曜
+		closedLog(1, at(3, 22, 0), at(3, 23, 30)), // 日曜の夜
+		closedLog(1, at(5, 8, 0), at(5, 10, 0)),
+		closedLog(1, at(5, 19, 0), at(5, 21, 0)), // 同じ日の2回目
+		// チェックアウトしていないセッションは数えない
+		{UserID: 1, CheckInAt: at(6, 7, 0)},
+	}
+	e := newAchievementEvaluator(logs, time.UTC, nil, HolidayRules{})
+
+	tests := []struct {
+		name          string
+		conditionType string
+		value         domain.JSONB
+		want          AchievementProgress
+		wantErr       error
+	}{
+		{"初回", ConditionFirstTime, domain.JSONB{}, AchievementProgress{Current: 1, Target: 1}, nil},
+		{"累計時間は切り捨て", ConditionTotalHours, domain.JSONB{"hours": float64(12)}, AchievementProgress{Current: 11, Target: 12}, nil},
+		{"累計日数は同じ日を重複して数えない", ConditionTotalDays, domain.JSONB{"days": float64(4)}, AchievementProgress{Current: 4, Target: 4}, nil},
+		{"連続日数は来なかった日で途切れる", ConditionStreakDays, domain.JSONB{"days": float64(3)}, AchievementProgress{Current: 3, Target: 3}, nil},
+		{"早朝は最初のチェックインで判定", ConditionEarlyCheckIn, domain.JSONB{"days": float64(3), "time": "10:00"}, AchievementProgress{Current: 2, Target: 3}, nil},
+		{"夜", ConditionLateCheckIn, domain.JSONB{"count": float64(2), "time": "18:00:00"}, AchievementProgress{Current: 2, Target: 2}, nil},
+		{"土日", ConditionWeekendCheckIn, domain.JSONB{"count": float64(5)}, AchievementProgress{Current: 2, Target: 5}, nil},
+		{"手動付与は解除しない", ConditionManual, domain.JSONB{}, AchievementProgress{Current: 0, Target: 1}, nil},
+		{"未対応の条件タイプ", "unknown", domain.JSONB{}, AchievementProgress{}, ErrUnknownConditionType},
+		{"目標値が無い", ConditionTotalDays, domain.JSONB{}, AchievementProgress{}, ErrInvalidConditionValue},
+		{"目標値が文字列", ConditionTotalHours, domain.JSONB{"hours": "10"}, AchievementProgress{}, ErrInvalidConditionValue},
+		{"時刻の形式", ConditionEarlyCheckIn, domain.JSONB{"days": float64(3), "time": "朝10時"}, AchievementProgress{}, ErrInvalidConditionValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.Evaluate(domain.Achievement{Code: tt.name, ConditionType: tt.conditionType, ConditionValue: tt.value})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+)
+
+// settingAchievementAnnouncements 出席による称号の解除を研究室全体に知らせるか（true/false、未設定の場合は知らせない）。
+// 教員・管理者による手動付与は設定に関わらず知らせる
+const settingAchievementAnnouncements = "achievement_announcements"
+
+// AchievementUnlocked achievement_unlocked・achievement_announcement イベントのペイロード
+type AchievementUnlocked struct {
+	UserID       uint                 `json:"user_id"`
+	DisplayName  string               `json:"display_name"`
+	Achievements []domain.Achievement `json:"achievements"`
+}
+
+// achievementNotifier 称号の解除の送り先（*ws.Hub）
+type achievementNotifier interface {
+	SendToUser(userID uint, msg interface{})
+	BroadcastMessage(msg interface{})
+}
+
+// evaluateAchievements チェックアウト後に称号を判定し、解除した称号を配信する。
+// チェックアウトのリクエストが終わった後に動くため、ctx にはリクエストから切り離したものを渡す
+func (s *attendanceService) evaluateAchievements(ctx context.Context, user domain.User) {
+	unlocked, err := s.achService.CheckAndUnlock(ctx, user.ID)
+	if err != nil {
+		// 途中で失敗した場合も、それまでに解除した称号は配信する
+		log.Printf("achievement evaluation failed for user %d: %v", user.ID, err)
+	}
+	if len(unlocked) == 0 {
+		return
+	}
+
+	notifyAchievementsUnlocked(s.hub, user, unlocked, announcesAchievements(ctx, s.settingsRepo))
+}
+
+// notifyAchievementsUnlocked 解除した称号を achievement_unlocked イベントで本人に配信する。
+// announce の場合は achievement_announcement イベントで全員にも知らせるが、
+// 全員に配信するため、ログインしていない閲覧者に出席データを公開しているユーザーに限る
+func notifyAchievementsUnlocked(hub achievementNotifier, user domain.User, unlocked []domain.Achievement, announce bool) {
+	event := AchievementUnlocked{UserID: user.ID, DisplayName: user.DisplayName, Achievements: unlocked}
+	hub.SendToUser(user.ID, map[string]interface{}{
+		"type":    "achievement_unlocked",
+		"payload": event,
+	})
+	if announce && user.IsVisibleTo(nil) {
+		hub.BroadcastMessage(map[string]interface{}{
+			"type":    "achievement_announcement",
+			"payload": event,
+		})
+	}
+}
+
+// announcesAchievements achievement_announcements 設定を読み込む
+func announcesAchievements(ctx context.Context, settingsRepo repository.SettingsRepository) bool {
+	var enabled bool
+	if err := settingsRepo.GetValue(ctx, settingAchievementAnnouncements, &enabled); err != nil {
+		return false
+	}
+	return enabled
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+// fakeAchievementNotifier 送ったイベントの種類を記録する achievementNotifier
+type fakeAchievementNotifier struct {
+	sent      map[uint][]string
+	broadcast []string
+}
+
+func eventType(msg interface{}) string {
+	return msg.(map[string]interface{})["type"].(string)
+}
+
+func (n *fakeAchievementNotifier) SendToUser(userID uint, msg interface{}) {
+	if n.sent == nil {
+		n.sent = make(map[uint][]string)
+	}
+	n.sent[userID] = append(n.sent[userID], eventType(msg))
+}
+
+func (n *fakeAchievementNotifier) BroadcastMessage(msg interface{}) {
+	n.broadcast = append(n.broadcast, eventType(msg))
+}
+
+func TestNotifyAchievementsUnlocked(t *testing.T) {
+	unlocked := []domain.Achievement{{ID: 1, Code: "first"}}
+	tests := []struct {
+		name       string
+		visibility string
+		announce   bool
+		wantAll    bool
+	}{
+		{"本人のみ", domain.VisibilityPublic, false, false},
+		{"研究室全体にも知らせる", domain.VisibilityPublic, true, true},
+		{"メンバー限定のユーザーは全体に知らせない", domain.VisibilityMembers, true, false},
+		{"非公開のユーザーは全体に知らせない", domain.VisibilityPrivate, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := &fakeAchievementNotifier{}
+			notifyAchievementsUnlocked(hub, domain.User{ID: 1, ProfileVisibility: tt.visibility}, unlocked, tt.announce)
+
+			if got := hub.sent[1]; len(got) != 1 || got[0] != "achievement_unlocked" {
+				t.Errorf("sent to user = %v, want [achievement_unlocked]", got)
+			}
+			if len(hub.sent) != 1 {
+				t.Errorf("sent to other users: %v", hub.sent)
+			}
+			if tt.wantAll {
+				if len(hub.broadcast) != 1 || hub.broadcast[0] != "achievement_announcement" {
+					t.Errorf("broadcast = %v, want [achievement_announcement]", hub.broadcast)
+				}
+			} else if len(hub.broadcast) != 0 {
+				t.Errorf("broadcast = %v, want none", hub.broadcast)
+			}
+		})
+	}
+}
+
+func TestAnnouncesAchievements(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings fakeSettingValues
+		want     bool
+	}{
+		{"未設定", fakeSettingValues{}, false},
+		{"有効", fakeSettingValues{settingAchievementAnnouncements: true}, true},
+		{"無効", fakeSettingValues{settingAchievementAnnouncements: false}, false},
+		{"真偽値でない", fakeSettingValues{settingAchievementAnnouncements: "yes"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := announcesAchievements(context.Background(), tt.settings); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// ctxRecordingAchievementService CheckAndUnlock に渡された ctx の状態を送る AchievementService
+type ctxRecordingAchievementService struct {
+	AchievementService
+	ctxErrs chan error
+}
+
+func (s ctxRecordingAchievementService) CheckAndUnlock(ctx context.Context, userID uint) ([]domain.Achievement, error) {
+	s.ctxErrs <- ctx.Err()
+	return nil, nil
+}
+
+func TestAfterCheckOut_DetachedFromRequestContext(t *testing.T) {
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	repo := &fakeSessionRepo{logs: []domain.CheckInLog{{ID: 1, UserID: 1, CheckInAt: now.Add(-11 * time.Hour)}}}
+	achievements := ctxRecordingAchievementService{ctxErrs: make(chan error, 1)}
+	s := NewAttendanceService(repo, fakeSettingValues{}, newRunningHub(), achievements, &fakeDailyService{},
+		nopRankingInvalidator{}, nopRankingUpdateNotifier{}, time.UTC)
+
+	// リクエストの ctx が取り消されていても、称号の判定は続ける
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.AutoCheckOut(ctx, now); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-achievements.ctxErrs:
+		if err != nil {
+			t.Errorf("achievement evaluation got a cancelled context: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("achievements were not evaluated")
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"gorm.io/gorm"
+)
+
+// AchievementRecomputeResult 称号の再計算の結果
+type AchievementRecomputeResult struct {
+	// Unlocks 新たに付与する（apply の場合は付与した）称号
+	Unlocks []RecomputedAchievement `json:"unlocks"`
+	// UsersChecked 出席履歴を辿り直したユーザー数
+	UsersChecked int  `json:"users_checked"`
+	Applied      bool `json:"applied"`
+}
+
+// RecomputedAchievement 再計算で付与される称号
+type RecomputedAchievement struct {
+	UserID          uint      `json:"user_id"`
+	Username        string    `json:"username"`
+	AchievementID   uint      `json:"achievement_id"`
+	AchievementCode string    `json:"achievement_code"`
+	AchievementName string    `json:"achievement_name"`
+	PointsReward    int       `json:"points_reward"`
+	AchievedAt      time.Time `json:"achieved_at"`
+}
+
+// UserIDs 称号が付与されるユーザー（重複なし）
+func (r *AchievementRecomputeResult) UserIDs() []uint {
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, u := range r.Unlocks {
+		if !seen[u.UserID] {
+			seen[u.UserID] = true
+			ids = append(ids, u.UserID)
+		}
+	}
+	return ids
+}
+
+func (s *achievementService) Recompute(ctx context.Context, userID uint, apply bool) (*AchievementRecomputeResult, error) {
+	var users []domain.User
+	if userID != 0 {
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserNotFound
+			}
+			return nil, err
+		}
+		users = []domain.User{*user}
+	} else {
+		all, err := s.userRepo.FindAll()
+		if err != nil {
+			return nil, err
+		}
+		users = all
+	}
+
+	achievements, err := s.repo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := loadHolidayCalendar(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadHolidayRules(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AchievementRecomputeResult{Unlocks: []RecomputedAchievement{}, Applied: apply}
+	for _, user := range users {
+		owned, err := s.repo.GetUserAchievements(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		unlockedIDs := make(map[uint]bool, len(owned))
+		for _, ua := range owned {
+			unlockedIDs[ua.AchievementID] = true
+		}
+		history, err := s.logRepo.GetUserHistory(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.UsersChecked++
+
+		for _, ach := range achievements {
+			if unlockedIDs[ach.ID] {
+				continue
+			}
+			achievedAt, ok, err := firstAchievedAt(history, ach, s.loc, holidays, rules)
+			if err != nil {
+				if !errors.Is(err, ErrUnknownConditionType) {
+					log.Printf("achievement %s: %v", ach.Code, err)
+				}
+				continue
+			}
+			if !ok {
+				continue
+			}
+			if apply {
+				ua := &domain.UserAchievement{UserID: user.ID, AchievementID: ach.ID, AchievedAt: achievedAt}
+				if err := s.unlock(ctx, ua, ach); err != nil {
+					return result, err
+				}
+			}
+			result.Unlocks = append(result.Unlocks, RecomputedAchievement{
+				UserID:          user.ID,
+				Username:        user.Username,
+				AchievementID:   ach.ID,
+				AchievementCode: ach.Code,
+				AchievementName: ach.Name,
+				PointsReward:    ach.PointsReward,
+				AchievedAt:      achievedAt,
+			})
+		}
+	}
+	return result, nil
+}
+
+// firstAchievedAt セッションをチェックアウト順に辿り、称号の条件を初めて満たしたチェックアウトの日時を返す。
+// どの条件も出席が増えるほど進捗が減ることはないため、満たすまでに必要なセッション数を二分探索で求める
+func firstAchievedAt(logs []domain.CheckInLog, ach domain.Achievement, loc *time.Location, holidays holidayCalendar, rules HolidayRules) (time.Time, bool, error) {
+	var closed []domain.CheckInLog
+	for _, l := range logs {
+		if l.CheckOutAt != nil {
+			closed = append(closed, l)
+		}
+	}
+	sort.SliceStable(closed, func(i, j int) bool { return closed[i].CheckOutAt.Before(*closed[j].CheckOutAt) })
+
+	achievedWith := func(n int) (bool, error) {
+		progress, err := newAchievementEvaluator(closed[:n], loc, holidays, rules).Evaluate(ach)
+		if err != nil {
+			return false, err
+		}
+		return progress.Achieved(), nil
+	}
+
+	ok, err := achievedWith(len(closed))
+	if err != nil || !ok || len(closed) == 0 {
+		return time.Time{}, false, err
+	}
+	// closed[:hi] では満たし、closed[:lo] では満たさない
+	lo, hi := 0, len(closed)
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		ok, err := achievedWith(mid)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return *closed[hi-1].CheckOutAt, true, nil
+}
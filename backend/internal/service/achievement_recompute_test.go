@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+func TestFirstAchievedAt(t *testing.T) {
+	loc := time.UTC
+	// 5/1〜5/5 に毎日2時間、5/4 だけ来なかった
+	var logs []domain.CheckInLog
+	for _, d := range []int{1, 2, 3, 5} {
+		in := time.Date(2026, 5, d, 10, 0, 0, 0, loc)
+		out := in.Add(2 * time.Hour)
+		logs = append(logs, domain.CheckInLog{UserID: 1, CheckInAt: in, CheckOutAt: &out})
+	}
+	// チェックアウトしていないセッションは数えない
+	logs = append(logs, domain.CheckInLog{UserID: 1, CheckInAt: time.Date(2026, 5, 6, 10, 0, 0, 0, loc)})
+
+	checkOut := func(d int) time.Time { return time.Date(2026, 5, d, 12, 0, 0, 0, loc) }
+	tests := []struct {
+		name      string
+		condition string
+		value     domain.JSONB
+		want      time.Time
+		ok        bool
+	}{
+		{"初回", ConditionFirstTime, domain.JSONB{}, checkOut(1), true},
+		{"3日連続", ConditionStreakDays, domain.JSONB{"days": float64(3)}, checkOut(3), true},
+		{"累計4日", ConditionTotalDays, domain.JSONB{"days": float64(4)}, checkOut(5), true},
+		{"累計5時間", ConditionTotalHours, domain.JSONB{"hours": float64(5)}, checkOut(3), true},
+		{"4日連続は未達成", ConditionStreakDays, domain.JSONB{"days": float64(4)}, time.Time{}, false},
+		{"手動付与は出席では解除しない", ConditionManual, domain.JSONB{}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ach := domain.Achievement{Code: tt.name, ConditionType: tt.condition, ConditionValue: tt.value}
+			got, ok, err := firstAchievedAt(logs, ach, loc, nil, HolidayRules{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("got %v %v, want %v %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
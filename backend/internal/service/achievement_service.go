@@ -2,99 +2,222 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log"
 	"time"
 
 	"github.com/kasa021/watabe-lab-app/internal/domain"
 	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/ws"
+)
+
+var (
+	ErrUserNotFound          = errors.New("user not found")
+	ErrNotManualAchievement  = errors.New("achievement is not awarded manually")
+	ErrAchievementAwarded    = errors.New("achievement already unlocked")
+	ErrAchievementNotAwarded = errors.New("achievement not unlocked")
+	ErrAwardNoteRequired     = errors.New("note is required")
+	ErrAchievementInactive   = errors.New("achievement is inactive")
 )
 
 type AchievementService interface {
 	GetAchievements(ctx context.Context) ([]domain.Achievement, error)
 	GetUserAchievements(ctx context.Context, userID uint) ([]domain.UserAchievement, error)
-	CheckAndUnlock(ctx context.Context, userID uint, triggerType string, value interface{}) ([]domain.Achievement, error)
+	// GetProgress は有効な全ての称号について、ユーザーの進捗と解除日時を返す
+	GetProgress(ctx context.Context, userID uint) ([]AchievementStatus, error)
+	// CheckAndUnlock はユーザーの出席履歴を全ての有効な称号の条件で評価し、新たに解除した称号を返す
+	CheckAndUnlock(ctx context.Context, userID uint) ([]domain.Achievement, error)
+	// Recompute は出席履歴を最初から辿り直し、条件を満たしていたのに付与されていない称号を
+	// 条件を満たした時点の日時で付与する。userID が 0 の場合は全ユーザー、apply が false の場合は確認のみ行う
+	Recompute(ctx context.Context, userID uint, apply bool) (*AchievementRecomputeResult, error)
+	// Award は手動付与の称号（condition_type が manual）をユーザーに付与し、本人と研究室全体に知らせる
+	Award(ctx context.Context, actorID, achievementID, userID uint, note string) (*domain.UserAchievement, error)
+	// Revoke は手動付与した称号を取り消し、報酬のポイントも取り消す
+	Revoke(ctx context.Context, actorID, achievementID, userID uint, note string) error
+	// GetAwardLogs は手動付与・取り消しの履歴を新しい順に返す（userID が 0 の場合は全ユーザー）
+	GetAwardLogs(ctx context.Context, userID uint) ([]domain.AchievementAwardLog, error)
+}
+
+// AchievementStatus 称号に対するユーザーの進捗
+type AchievementStatus struct {
+	Achievement domain.Achievement `json:"achievement"`
+	Current     int                `json:"current"`
+	Target      int                `json:"target"`
+	// Percent 進捗の割合（0〜100）。解除済みの場合は常に100
+	Percent    int        `json:"percent"`
+	Unlocked   bool       `json:"unlocked"`
+	AchievedAt *time.Time `json:"achieved_at"`
 }
 
 type achievementService struct {
-	repo     repository.AchievementRepository
-	userRepo repository.UserRepository
-	// logRepo  repository.AttendanceRepository // 複雑な条件判定に必要なら追加
+	repo         repository.AchievementRepository
+	userRepo     repository.UserRepository
+	logRepo      repository.AttendanceRepository
+	settingsRepo repository.SettingsRepository
+	pointRepo    repository.PointRepository
+	// hub 手動付与の配信先（CLI では nil）
+	hub *ws.Hub
+	loc *time.Location
 }
 
-func NewAchievementService(repo repository.AchievementRepository, userRepo repository.UserRepository) AchievementService {
+func NewAchievementService(repo repository.AchievementRepository, userRepo repository.UserRepository, logRepo repository.AttendanceRepository, settingsRepo repository.SettingsRepository, pointRepo repository.PointRepository, hub *ws.Hub, loc *time.Location) AchievementService {
 	return &achievementService{
-		repo:     repo,
-		userRepo: userRepo,
+		repo:         repo,
+		userRepo:     userRepo,
+		logRepo:      logRepo,
+		settingsRepo: settingsRepo,
+		pointRepo:    pointRepo,
+		hub:          hub,
+		loc:          loc,
 	}
 }
 
 func (s *achievementService) GetAchievements(ctx context.Context) ([]domain.Achievement, error) {
-	return s.repo.FindAll(ctx)
+	// 無効にした称号は一覧に出さない（獲得済みの称号は GetUserAchievements で返る）
+	return s.repo.FindActive(ctx)
 }
 
 func (s *achievementService) GetUserAchievements(ctx context.Context, userID uint) ([]domain.UserAchievement, error) {
 	return s.repo.GetUserAchievements(ctx, userID)
 }
 
-// CheckAndUnlock は指定されたトリガー（例: "check_in_count"）に基づいて実績を判定し、解除する
-func (s *achievementService) CheckAndUnlock(ctx context.Context, userID uint, triggerType string, value interface{}) ([]domain.Achievement, error) {
-	allAchievements, err := s.repo.FindAll(ctx)
+func (s *achievementService) GetProgress(ctx context.Context, userID uint) ([]AchievementStatus, error) {
+	achievements, err := s.repo.FindActive(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var unlocked []domain.Achievement
+	owned, err := s.repo.GetUserAchievements(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	achievedAt := make(map[uint]time.Time, len(owned))
+	for _, ua := range owned {
+		achievedAt[ua.AchievementID] = ua.AchievedAt
+	}
+
+	evaluator, err := s.evaluatorFor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]AchievementStatus, 0, len(achievements))
+	for _, ach := range achievements {
+		status := AchievementStatus{Achievement: ach}
+		if at, ok := achievedAt[ach.ID]; ok {
+			status.Unlocked = true
+			status.AchievedAt = &at
+		}
 
-	for _, ach := range allAchievements {
-		// 既に解除済みかチェック
-		hasUnlocked, err := s.repo.HasUnlocked(ctx, userID, ach.ID)
+		progress, err := evaluator.Evaluate(ach)
 		if err != nil {
-			continue // エラーでも他を続行
+			if !errors.Is(err, ErrUnknownConditionType) {
+				log.Printf("achievement %s: %v", ach.Code, err)
+			}
+			// 判定できない称号は解除済みの場合だけ返す
+			if !status.Unlocked {
+				continue
+			}
 		}
-		if hasUnlocked {
-			continue
+		status.Current, status.Target = progress.Current, progress.Target
+		status.Percent = progress.Percent()
+		if status.Unlocked {
+			status.Percent = 100
 		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
 
-		// 条件判定（簡易実装）
-		// マスタデータの ConditionType と、引数の triggerType が一致する場合のみ判定
-		if ach.ConditionType != triggerType {
-			continue
-		}
+func (s *achievementService) CheckAndUnlock(ctx context.Context, userID uint) ([]domain.Achievement, error) {
+	achievements, err := s.repo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owned, err := s.repo.GetUserAchievements(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	unlockedIDs := make(map[uint]bool, len(owned))
+	for _, ua := range owned {
+		unlockedIDs[ua.AchievementID] = true
+	}
 
-		shouldUnlock := false
+	evaluator, err := s.evaluatorFor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-		// ConditionValue は JSONB (map[string]interface{})
-		targetVal, ok := ach.ConditionValue["target"].(float64) // JSONの数値はfloat64で来ることが多い
-		if !ok {
+	var unlocked []domain.Achievement
+	for _, ach := range achievements {
+		if unlockedIDs[ach.ID] {
 			continue
 		}
-		intTarget := int(targetVal)
-
-		// トリガーごとの判定ロジック
-		switch triggerType {
-		case "total_check_in": // 累計回数
-			currentCount, ok := value.(int)
-			if ok && currentCount >= intTarget {
-				shouldUnlock = true
-			}
-		case "total_duration": // 累計時間（分）
-			currentDuration, ok := value.(int)
-			if ok && currentDuration >= intTarget {
-				shouldUnlock = true
+
+		progress, err := evaluator.Evaluate(ach)
+		if err != nil {
+			// 未対応の条件タイプや不正な条件値は他の称号の判定を止めない
+			if !errors.Is(err, ErrUnknownConditionType) {
+				log.Printf("achievement %s: %v", ach.Code, err)
 			}
-			// case "streak": // ストリーク（別途DB参照が必要かも）
+			continue
+		}
+		if !progress.Achieved() {
+			continue
 		}
 
-		if shouldUnlock {
-			ua := &domain.UserAchievement{
-				UserID:        userID,
-				AchievementID: ach.ID,
-				AchievedAt:    time.Now(),
-			}
-			if err := s.repo.CreateUserAchievement(ctx, ua); err == nil {
-				unlocked = append(unlocked, ach)
-			}
+		ua := &domain.UserAchievement{UserID: userID, AchievementID: ach.ID, AchievedAt: time.Now()}
+		if err := s.unlock(ctx, ua, ach); err != nil {
+			return unlocked, err
 		}
+		unlocked = append(unlocked, ach)
 	}
 
 	return unlocked, nil
 }
+
+// evaluatorFor ユーザーの全期間の出席履歴から評価器を作成する
+func (s *achievementService) evaluatorFor(ctx context.Context, userID uint) (*achievementEvaluator, error) {
+	history, err := s.logRepo.GetUserHistory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := loadHolidayCalendar(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadHolidayRules(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+	return newAchievementEvaluator(history, s.loc, holidays, rules), nil
+}
+
+// unlock 称号を付与し、報酬のポイントを加える。ポイントの日付は ua.AchievedAt にする
+func (s *achievementService) unlock(ctx context.Context, ua *domain.UserAchievement, ach domain.Achievement) error {
+	return s.repo.Transaction(ctx, func(repo repository.AchievementRepository, pointRepo repository.PointRepository) error {
+		return unlockAchievement(ctx, repo, pointRepo, ua, ach)
+	})
+}
+
+// unlockAchievement 渡されたリポジトリで称号の獲得記録と報酬のポイントを記録する（トランザクション内で使う）
+func unlockAchievement(ctx context.Context, repo repository.AchievementRepository, pointRepo repository.PointRepository, ua *domain.UserAchievement, ach domain.Achievement) error {
+	if err := repo.CreateUserAchievement(ctx, ua); err != nil {
+		return err
+	}
+	if ach.PointsReward != 0 {
+		achievementID := ach.ID
+		if err := pointRepo.CreateTransaction(ctx, &domain.PointTransaction{
+			UserID:        ua.UserID,
+			Source:        domain.PointSourceAchievement,
+			Amount:        ach.PointsReward,
+			AchievementID: &achievementID,
+			Description:   ach.Name,
+			CreatedAt:     ua.AchievedAt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
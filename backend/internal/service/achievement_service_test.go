@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+)
+
+// fakeProgressRepo 有効な称号と獲得済みの称号だけを持つ AchievementRepository
+type fakeProgressRepo struct {
+	repository.AchievementRepository
+	achievements []domain.Achievement
+	owned        []domain.UserAchievement
+}
+
+func (r *fakeProgressRepo) FindActive(ctx context.Context) ([]domain.Achievement, error) {
+	return r.achievements, nil
+}
+
+func (r *fakeProgressRepo) GetUserAchievements(ctx context.Context, userID uint) ([]domain.UserAchievement, error) {
+	return r.owned, nil
+}
+
+// fakeUserLogs ユーザーの出席履歴だけを持つ AttendanceRepository
+type fakeUserLogs struct {
+	repository.AttendanceRepository
+	logs []domain.CheckInLog
+}
+
+func (r fakeUserLogs) GetUserHistory(ctx context.Context, userID uint) ([]domain.CheckInLog, error) {
+	return r.logs, nil
+}
+
+func TestAchievementService_GetProgress(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 5, day, hour, 0, 0, 0, time.UTC) }
+	days := func(n int) map[string]interface{} { return map[string]interface{}{"days": float64(n)} }
+	achievedAt := at(2, 9)
+	repo := &fakeProgressRepo{
+		achievements: []domain.Achievement{
+			{ID: 1, Code: "days3", ConditionType: ConditionTotalDays, ConditionValue: days(3)},
+			{ID: 2, Code: "days4", ConditionType: ConditionTotalDays, ConditionValue: days(4)},
+			{ID: 3, Code: "future", ConditionType: "future_condition"},
+			{ID: 4, Code: "future_owned", ConditionType: "future_condition"},
+			{ID: 5, Code: "manual", ConditionType: ConditionManual},
+			{ID: 6, Code: "manual_owned", ConditionType: ConditionManual},
+			{ID: 7, Code: "broken", ConditionType: ConditionTotalDays, ConditionValue: days(-1)},
+		},
+		owned: []domain.UserAchievement{
+			// 出席の修正で条件を満たさなくなった称号も獲得済みのまま
+			{AchievementID: 1, AchievedAt: achievedAt},
+			{AchievementID: 4, AchievedAt: achievedAt},
+			{AchievementID: 6, AchievedAt: achievedAt},
+		},
+	}
+	logs := fakeUserLogs{logs: []domain.CheckInLog{closedLog(1, at(1, 9), at(1, 12))}}
+	s := NewAchievementService(repo, nil, logs, fakeSettingValues{}, nil, nil, time.UTC)
+
+	statuses, err := s.GetProgress(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]AchievementStatus, len(statuses))
+	for _, st := range statuses {
+		got[st.Achievement.Code] = st
+	}
+
+	tests := []struct {
+		code     string
+		current  int
+		target   int
+		percent  int
+		unlocked bool
+	}{
+		{"days3", 1, 3, 100, true},
+		{"days4", 1, 4, 25, false},
+		{"future_owned", 0, 0, 100, true},
+		{"manual", 0, 1, 0, false},
+		{"manual_owned", 0, 1, 100, true},
+	}
+	for _, tt := range tests {
+		st, ok := got[tt.code]
+		if !ok {
+			t.Errorf("%s is missing", tt.code)
+			continue
+		}
+		if st.Current != tt.current || st.Target != tt.target || st.Percent != tt.percent || st.Unlocked != tt.unlocked {
+			t.Errorf("%s = %d/%d %d%% unlocked=%v, want %d/%d %d%% unlocked=%v",
+				tt.code, st.Current, st.Target, st.Percent, st.Unlocked, tt.current, tt.target, tt.percent, tt.unlocked)
+		}
+		if tt.unlocked && (st.AchievedAt == nil || !st.AchievedAt.Equal(achievedAt)) {
+			t.Errorf("%s achieved_at = %v, want %v", tt.code, st.AchievedAt, achievedAt)
+		}
+	}
+	// 判定できない未獲得の称号は返さない
+	for _, code := range []string{"future", "broken"} {
+		if _, ok := got[code]; ok {
+			t.Errorf("%s should not be listed", code)
+		}
+	}
+	if len(statuses) != len(tests) {
+		t.Errorf("statuses = %d, want %d", len(statuses), len(tests))
+	}
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/ws"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrInvalidSession  = errors.New("invalid session")
+	ErrReasonRequired  = errors.New("reason is required")
+	ErrSessionOverlap  = errors.New("session overlaps another session")
+)
+
+// CheckInMethodAdmin 管理者が作成した出席記録の check_in_method
+const CheckInMethodAdmin = "admin"
+
+// auditLogLimit 修正履歴の取得件数の上限
+const auditLogLimit = 200
+
+// SessionInput 管理者による出席記録の作成・修正内容
+type SessionInput struct {
+	UserID     uint      `json:"user_id"`
+	CheckInAt  time.Time `json:"check_in_at" binding:"required"`
+	CheckOutAt time.Time `json:"check_out_at" binding:"required"`
+	Reason     string    `json:"reason"`
+}
+
+// AttendanceAdminService 管理者・教員による出席記録の修正
+type AttendanceAdminService interface {
+	CreateSession(ctx context.Context, actorID uint, input *SessionInput) (*domain.CheckInLog, error)
+	UpdateSession(ctx context.Context, actorID uint, logID uint, input *SessionInput) (*domain.CheckInLog, error)
+	DeleteSession(ctx context.Context, actorID uint, logID uint, reason string) error
+	// ApplySession は出席記録を作成（logID が 0 の場合）・修正する。
+	// 訂正申請の承認と同じトランザクションで実行するため、トランザクション内のリポジトリを受け取る。
+	// コミット後に SessionApplied を呼ぶ
+	ApplySession(ctx context.Context, tx repository.AttendanceRepository, actorID uint, logID uint, input *SessionInput) (*SessionChange, error)
+	// SessionApplied はコミットされた変更について、称号の再判定・ランキングの無効化・在室者一覧への配信を行う
+	SessionApplied(ctx context.Context, change *SessionChange)
+	// GetAuditLogs は修正履歴を新しい順に返す（userID が 0 の場合は全ユーザー）
+	GetAuditLogs(ctx context.Context, userID uint) ([]domain.AttendanceAuditLog, error)
+}
+
+// SessionChange 出席記録の変更前後（作成の場合 Before、削除の場合 After は nil）
+type SessionChange struct {
+	Before *domain.CheckInLog
+	After  *domain.CheckInLog
+}
+
+type attendanceAdminService struct {
+	repo         repository.AttendanceRepository
+	userRepo     repository.UserRepository
+	dailyService DailyAttendanceService
+	achService   AchievementService
+	rankings     RankingInvalidator
+	hub          *ws.Hub
+}
+
+func NewAttendanceAdminService(repo repository.AttendanceRepository, userRepo repository.UserRepository, dailyService DailyAttendanceService, achService AchievementService, rankings RankingInvalidator, hub *ws.Hub) AttendanceAdminService {
+	return &attendanceAdminService{
+		repo:         repo,
+		userRepo:     userRepo,
+		dailyService: dailyService,
+		achService:   achService,
+		rankings:     rankings,
+		hub:          hub,
+	}
+}
+
+func (s *attendanceAdminService) CreateSession(ctx context.Context, actorID uint, input *SessionInput) (*domain.CheckInLog, error) {
+	return s.applyAndNotify(ctx, actorID, 0, input)
+}
+
+func (s *attendanceAdminService) UpdateSession(ctx context.Context, actorID uint, logID uint, input *SessionInput) (*domain.CheckInLog, error) {
+	if logID == 0 {
+		return nil, ErrSessionNotFound
+	}
+	return s.applyAndNotify(ctx, actorID, logID, input)
+}
+
+// applyAndNotify ApplySession を単独のトランザクションで実行し、コミット後の処理を行う
+func (s *attendanceAdminService) applyAndNotify(ctx context.Context, actorID uint, logID uint, input *SessionInput) (*domain.CheckInLog, error) {
+	var change *SessionChange
+	err := s.repo.Transaction(ctx, func(tx repository.AttendanceRepository) error {
+		var err error
+		change, err = s.ApplySession(ctx, tx, actorID, logID, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.SessionApplied(ctx, change)
+	return change.After, nil
+}
+
+func (s *attendanceAdminService) ApplySession(ctx context.Context, tx repository.AttendanceRepository, actorID uint, logID uint, input *SessionInput) (*SessionChange, error) {
+	if err := validateSessionInput(input, time.Now()); err != nil {
+		return nil, err
+	}
+	if logID == 0 {
+		return s.createSession(ctx, tx, actorID, input)
+	}
+	return s.updateSession(ctx, tx, actorID, logID, input)
+}
+
+func (s *attendanceAdminService) createSession(ctx context.Context, tx repository.AttendanceRepository, actorID uint, input *SessionInput) (*SessionChange, error) {
+	if input.UserID == 0 {
+		return nil, fmt.Errorf("user_id is required: %w", ErrInvalidSession)
+	}
+	if _, err := s.userRepo.FindByID(input.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	log := &domain.CheckInLog{
+		UserID:        input.UserID,
+		CheckInMethod: CheckInMethodAdmin,
+	}
+	applySessionTimes(log, input)
+
+	if err := ensureNoOverlap(ctx, tx, log); err != nil {
+		return nil, err
+	}
+	if err := tx.Create(ctx, log); err != nil {
+		return nil, err
+	}
+	if err := s.dailyService.RefreshDays(ctx, tx, log.UserID, log.CheckInAt, *log.CheckOutAt); err != nil {
+		return nil, err
+	}
+	if err := tx.CreateAuditLog(ctx, newAuditLog(domain.AuditActionCreate, actorID, input.Reason, nil, log)); err != nil {
+		return nil, err
+	}
+	return &SessionChange{After: log}, nil
+}
+
+func (s *attendanceAdminService) updateSession(ctx context.Context, tx repository.AttendanceRepository, actorID uint, logID uint, input *SessionInput) (*SessionChange, error) {
+	current, err := findSession(ctx, tx, logID)
+	if err != nil {
+		return nil, err
+	}
+	before := *current
+
+	applySessionTimes(current, input)
+	if err := ensureNoOverlap(ctx, tx, current); err != nil {
+		return nil, err
+	}
+	if err := tx.Update(ctx, current); err != nil {
+		return nil, err
+	}
+
+	// 修正前後の両方の日付を再集計する
+	if err := s.dailyService.RefreshDays(ctx, tx, before.UserID, before.CheckInAt, sessionEnd(&before)); err != nil {
+		return nil, err
+	}
+	if err := s.dailyService.RefreshDays(ctx, tx, current.UserID, current.CheckInAt, *current.CheckOutAt); err != nil {
+		return nil, err
+	}
+	if err := tx.CreateAuditLog(ctx, newAuditLog(domain.AuditActionUpdate, actorID, input.Reason, &before, current)); err != nil {
+		return nil, err
+	}
+	return &SessionChange{Before: &before, After: current}, nil
+}
+
+func (s *attendanceAdminService) DeleteSession(ctx context.Context, actorID uint, logID uint, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrReasonRequired
+	}
+
+	var deleted *domain.CheckInLog
+	err := s.repo.Transaction(ctx, func(tx repository.AttendanceRepository) error {
+		current, err := findSession(ctx, tx, logID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(ctx, current.ID); err != nil {
+			return err
+		}
+		if err := s.dailyService.RefreshDays(ctx, tx, current.UserID, current.CheckInAt, sessionEnd(current)); err != nil {
+			return err
+		}
+		deleted = current
+		return tx.CreateAuditLog(ctx, newAuditLog(domain.AuditActionDelete, actorID, reason, current, nil))
+	})
+	if err != nil {
+		return err
+	}
+	s.SessionApplied(ctx, &SessionChange{Before: deleted})
+	return nil
+}
+
+func (s *attendanceAdminService) SessionApplied(ctx context.Context, change *SessionChange) {
+	current := change.After
+	if current == nil {
+		current = change.Before
+	}
+	s.reevaluateAchievements(ctx, current.UserID)
+	s.rankings.Invalidate(current.UserID)
+
+	// チェックアウト忘れを修正・削除した場合は在室者一覧から外す
+	if change.Before != nil && change.Before.CheckOutAt == nil {
+		s.hub.BroadcastMessage(map[string]interface{}{
+			"type":    "check_out",
+			"payload": current,
+		})
+	}
+}
+
+func (s *attendanceAdminService) GetAuditLogs(ctx context.Context, userID uint) ([]domain.AttendanceAuditLog, error) {
+	return s.repo.GetAuditLogs(ctx, userID, auditLogLimit)
+}
+
+// reevaluateAchievements 修正後の出席履歴で称号を判定し、新たに解除した称号を本人に知らせる。
+// 修正で条件を満たさなくなった称号は取り消さない。出席記録の修正は確定しているため、判定の失敗はログに残すだけにする
+func (s *attendanceAdminService) reevaluateAchievements(ctx context.Context, userID uint) {
+	unlocked, err := s.achService.CheckAndUnlock(ctx, userID)
+	if err != nil {
+		log.Printf("achievement evaluation failed for user %d: %v", userID, err)
+	}
+	if len(unlocked) == 0 {
+		return
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		log.Printf("achievement notification failed for user %d: %v", userID, err)
+		return
+	}
+	notifyAchievementsUnlocked(s.hub, *user, unlocked, false)
+}
+
+// validateSessionInput 修正内容の妥当性を確認する
+func validateSessionInput(input *SessionInput, now time.Time) error {
+	if strings.TrimSpace(input.Reason) == "" {
+		return ErrReasonRequired
+	}
+	if !input.CheckOutAt.After(input.CheckInAt) {
+		return fmt.Errorf("check_out_at must be after check_in_at: %w", ErrInvalidSession)
+	}
+	if input.CheckOutAt.After(now) {
+		return fmt.Errorf("check_out_at must not be in the future: %w", ErrInvalidSession)
+	}
+	return nil
+}
+
+// applySessionTimes チェックイン・チェックアウト時刻を設定し、滞在時間を再計算する
+func applySessionTimes(log *domain.CheckInLog, input *SessionInput) {
+	checkIn := input.CheckInAt
+	checkOut := input.CheckOutAt
+	duration := int(checkOut.Sub(checkIn).Minutes())
+
+	log.CheckInAt = checkIn
+	log.CheckOutAt = &checkOut
+	log.DurationMinutes = &duration
+	log.IsAutoCheckout = false
+}
+
+func findSession(ctx context.Context, repo repository.AttendanceRepository, logID uint) (*domain.CheckInLog, error) {
+	log, err := repo.FindByID(ctx, logID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return log, nil
+}
+
+func ensureNoOverlap(ctx context.Context, repo repository.AttendanceRepository, log *domain.CheckInLog) error {
+	overlap, err := repo.HasOverlappingSession(ctx, log.UserID, log.CheckInAt, *log.CheckOutAt, log.ID)
+	if err != nil {
+		return err
+	}
+	if overlap {
+		return ErrSessionOverlap
+	}
+	return nil
+}
+
+// sessionEnd 集計の対象範囲を求めるためのセッション終了時刻（在室中なら現在時刻）
+func sessionEnd(log *domain.CheckInLog) time.Time {
+	if log.CheckOutAt != nil {
+		return *log.CheckOutAt
+	}
+	return time.Now()
+}
+
+func newAuditLog(action string, actorID uint, reason string, before, after *domain.CheckInLog) *domain.AttendanceAuditLog {
+	audit := &domain.AttendanceAuditLog{
+		Action:      action,
+		Reason:      strings.TrimSpace(reason),
+		BeforeValue: sessionSnapshot(before),
+		AfterValue:  sessionSnapshot(after),
+	}
+	if actorID != 0 {
+		audit.ActorID = &actorID
+	}
+	for _, log := range []*domain.CheckInLog{after, before} {
+		if log != nil {
+			audit.CheckInLogID = log.ID
+			audit.UserID = log.UserID
+			break
+		}
+	}
+	return audit
+}
+
+// sessionSnapshot 修正履歴に残すためにログをJSONBに変換する
+func sessionSnapshot(log *domain.CheckInLog) domain.JSONB {
+	if log == nil {
+		return nil
+	}
+	bytes, err := json.Marshal(log)
+	if err != nil {
+		return nil
+	}
+	var snapshot domain.JSONB
+	if err := json.Unmarshal(bytes, &snapshot); err != nil {
+		return nil
+	}
+	delete(snapshot, "user")
+	return snapshot
+}
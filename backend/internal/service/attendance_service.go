@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"math"
@@ -24,21 +25,36 @@ type AttendanceService interface {
 	CheckIn(ctx context.Context, userID uint, req *CheckInRequest) error
 	CheckOut(ctx context.Context, userID uint) error
 	GetActiveUsers(ctx context.Context) ([]domain.CheckInLog, error)
+	// GetSessionHistory はユーザーのセッション履歴を新しい順にページ単位で返す
+	GetSessionHistory(ctx context.Context, userID uint, query SessionHistoryQuery) (*SessionHistory, error)
+	// AutoCheckOut は auto_checkout_minutes を超えて開いたままのセッションを閉じ、閉じた件数を返す
+	AutoCheckOut(ctx context.Context, now time.Time) (int, error)
 }
 
+// defaultAutoCheckoutMinutes auto_checkout_minutes 設定が無い場合の上限（分）
+const defaultAutoCheckoutMinutes = 600
+
 type attendanceService struct {
 	repo         repository.AttendanceRepository
-	settingsRepo repository.SettingsRepository // Added
+	settingsRepo repository.SettingsRepository
 	hub          *ws.Hub
 	achService   AchievementService
+	dailyService DailyAttendanceService
+	rankings     RankingInvalidator
+	rankingFeed  RankingUpdateNotifier
+	loc          *time.Location
 }
 
-func NewAttendanceService(repo repository.AttendanceRepository, settingsRepo repository.SettingsRepository, hub *ws.Hub, achService AchievementService) AttendanceService {
+func NewAttendanceService(repo repository.AttendanceRepository, settingsRepo repository.SettingsRepository, hub *ws.Hub, achService AchievementService, dailyService DailyAttendanceService, rankings RankingInvalidator, rankingFeed RankingUpdateNotifier, loc *time.Location) AttendanceService {
 	return &attendanceService{
 		repo:         repo,
-		settingsRepo: settingsRepo, // Added
+		settingsRepo: settingsRepo,
 		hub:          hub,
 		achService:   achService,
+		dailyService: dailyService,
+		rankings:     rankings,
+		rankingFeed:  rankingFeed,
+		loc:          loc,
 	}
 }
 
@@ -47,7 +63,7 @@ type CheckInRequest struct {
 	WiFiSSID      string   `json:"wifi_ssid"`
 	GPSLatitude   *float64 `json:"gps_latitude"`
 	GPSLongitude  *float64 `json:"gps_longitude"`
-	ClientIP      string   `json:"-"` // Added for internal use
+	ClientIP      string   `json:"-"` // ハンドラーで接続元から設定する（リクエストでは受け取らない）
 }
 
 func calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
@@ -69,19 +85,13 @@ func (s *attendanceService) CheckIn(ctx context.Context, userID uint, req *Check
 	// 1. IP Address Validation
 	settingIP, err := s.settingsRepo.GetByKey(ctx, "allowed_ip_range")
 	if err == nil {
-		// Expecting JSON: {"ips": ["1.2.3.4", ...]}
-		if ips, ok := settingIP.Value["ips"].([]interface{}); ok {
-			isAllowed := false
-			for _, v := range ips {
-				if ip, ok := v.(string); ok && ip == req.ClientIP {
-					isAllowed = true
-					break
-				}
-				// TODO: CIDR support if needed
-			}
-			if !isAllowed {
-				return fmt.Errorf("研究室のWifiに接続してください (Your IP: %s): %w", req.ClientIP, ErrRestrictionViolation)
-			}
+		// Expecting JSON: {"ips": ["1.2.3.4", "10.0.0.0/8", "2001:db8::/32"], "deny": [...]}
+		restriction, err := parseIPRestriction(settingIP.Value)
+		if err != nil {
+			return fmt.Errorf("allowed_ip_range の設定が不正です: %w", err)
+		}
+		if err := restriction.Check(req.ClientIP); err != nil {
+			return err
 		}
 	} else {
 		// Just debugging: in production we might want to log this.
@@ -141,6 +151,7 @@ func (s *attendanceService) CheckIn(ctx context.Context, userID uint, req *Check
 	if err := s.repo.Create(ctx, log); err != nil {
 		return err
 	}
+	s.rankings.Invalidate(userID)
 
 	// ユーザー情報を含めてブロードキャストするために、再度取得（または手動で構築）
 	// ここではシンプルに、作成したログにユーザー情報をセットするためにリロードするか、
@@ -165,35 +176,107 @@ func (s *attendanceService) CheckOut(ctx context.Context, userID uint) error {
 		return err
 	}
 
-	now := time.Now()
-	log.CheckOutAt = &now
+	closed, err := s.closeSession(ctx, log, time.Now(), false)
+	if err != nil {
+		return err
+	}
+	if !closed {
+		// 自動終了と同時に行われ、先に終了されていた
+		return ErrNotCheckedIn
+	}
+
+	// 実績解除判定とランキングの変化の配信 (非同期)
+	go s.afterCheckOut([]domain.User{log.User})
+
+	return nil
+}
+
+// AutoCheckOut チェックアウト忘れのセッションを自動で終了する。
+// チェックアウト時刻は「チェックイン時刻 + 上限時間」に丸め、滞在時間が上限を超えないようにする。
+func (s *attendanceService) AutoCheckOut(ctx context.Context, now time.Time) (int, error) {
+	limit := s.autoCheckoutLimit(ctx)
+	staleLogs, err := s.repo.GetStaleCheckIns(ctx, now.Add(-limit))
+	if err != nil {
+		return 0, err
+	}
+
+	var users []domain.User
+	for i := range staleLogs {
+		stale := &staleLogs[i]
+		closed, err := s.closeSession(ctx, stale, stale.CheckInAt.Add(limit), true)
+		if err != nil {
+			log.Printf("auto checkout failed for log %d: %v", stale.ID, err)
+			continue
+		}
+		if closed {
+			users = append(users, stale.User)
+		}
+	}
+	if len(users) > 0 {
+		go s.afterCheckOut(users)
+	}
+	return len(users), nil
+}
+
+// afterCheckOut チェックアウトしたユーザーの称号を判定し、ランキングの変化を配信する。
+// 非同期で呼ぶため、リクエストの終了で取り消される ctx は使わない
+func (s *attendanceService) afterCheckOut(users []domain.User) {
+	for _, user := range users {
+		s.evaluateAchievements(context.Background(), user)
+		// 称号の報酬でポイントが変わるため
+		s.rankings.Invalidate(user.ID)
+	}
+	s.notifyRankingChanges()
+}
+
+// notifyRankingChanges チェックアウトで変わった今週・今月の順位を配信する
+func (s *attendanceService) notifyRankingChanges() {
+	if err := s.rankingFeed.NotifyChanges(context.Background()); err != nil {
+		log.Printf("ranking update notification failed: %v", err)
+	}
+}
+
+// autoCheckoutLimit auto_checkout_minutes 設定を読み込む。未設定・不正値の場合はデフォルト値を使う。
+func (s *attendanceService) autoCheckoutLimit(ctx context.Context) time.Duration {
+	minutes := defaultAutoCheckoutMinutes
+	var v int
+	if err := s.settingsRepo.GetValue(ctx, "auto_checkout_minutes", &v); err == nil && v > 0 {
+		minutes = v
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// closeSession セッションを指定時刻で終了し、check_out イベントを配信する。
+// ログの更新と日次集計の更新は同一トランザクションで行う。
+// 既に別の処理で終了されていた場合は何もせず false を返す
+func (s *attendanceService) closeSession(ctx context.Context, log *domain.CheckInLog, checkOutAt time.Time, auto bool) (bool, error) {
+	log.CheckOutAt = &checkOutAt
+	log.IsAutoCheckout = auto
 
 	// 滞在時間（分）計算
-	duration := int(now.Sub(log.CheckInAt).Minutes())
+	duration := int(checkOutAt.Sub(log.CheckInAt).Minutes())
 	log.DurationMinutes = &duration
 
-	if err := s.repo.Update(ctx, log); err != nil {
-		return err
+	var closed bool
+	err := s.repo.Transaction(ctx, func(tx repository.AttendanceRepository) error {
+		var err error
+		closed, err = tx.CloseSession(ctx, log)
+		if err != nil || !closed {
+			return err
+		}
+		return s.dailyService.RefreshDays(ctx, tx, log.UserID, log.CheckInAt, checkOutAt)
+	})
+	if err != nil || !closed {
+		return false, err
 	}
+	s.rankings.Invalidate(log.UserID)
 
 	// Broadcast check-out event
 	s.hub.BroadcastMessage(map[string]interface{}{
 		"type":    "check_out",
 		"payload": log,
 	})
-
-	// 実績解除判定 (非同期)
-	go func() {
-		bgCtx := context.Background()
-		// 累計回数判定 (とりあえず今回の1回をトリガーに全件チェック)
-		// FIXME: 本来は現在の数値を渡すべきだが、Service内でCountsを取得する実装が必要。
-		// ここでは簡易的に 0 を渡して、Service側で条件と一致するか見る (Service側も実装修正が必要)
-		// 一旦、トリガータイプだけ合わせておく。
-		s.achService.CheckAndUnlock(bgCtx, userID, "check_in_count", nil)
-		s.achService.CheckAndUnlock(bgCtx, userID, "total_duration", nil)
-	}()
-
-	return nil
+	return true, nil
 }
 
 func (s *attendanceService) GetActiveUsers(ctx context.Context) ([]domain.CheckInLog, error) {
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/ws"
+	"gorm.io/gorm"
+)
+
+// fakeSessionRepo セッションをメモリ上に持つ AttendanceRepository（チェックアウトと出席記録の修正で使うメソッドのみ）
+type fakeSessionRepo struct {
+	repository.AttendanceRepository
+	logs   []domain.CheckInLog
+	audits []domain.AttendanceAuditLog
+	// closedElsewhere 取得後に別の処理で終了されたことにするログの ID
+	closedElsewhere map[uint]bool
+}
+
+func (r *fakeSessionRepo) Create(ctx context.Context, log *domain.CheckInLog) error {
+	log.ID = uint(len(r.logs) + 1)
+	r.logs = append(r.logs, *log)
+	return nil
+}
+
+func (r *fakeSessionRepo) Update(ctx context.Context, log *domain.CheckInLog) error {
+	for i := range r.logs {
+		if r.logs[i].ID == log.ID {
+			r.logs[i] = *log
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeSessionRepo) FindByID(ctx context.Context, id uint) (*domain.CheckInLog, error) {
+	for _, l := range r.logs {
+		if l.ID == id {
+			return &l, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSessionRepo) Delete(ctx context.Context, id uint) error {
+	for i := range r.logs {
+		if r.logs[i].ID == id {
+			r.logs = append(r.logs[:i], r.logs[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) HasOverlappingSession(ctx context.Context, userID uint, from, to time.Time, excludeID uint) (bool, error) {
+	for _, l := range r.logs {
+		if l.UserID != userID || l.ID == excludeID {
+			continue
+		}
+		if l.CheckInAt.Before(to) && (l.CheckOutAt == nil || l.CheckOutAt.After(from)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeSessionRepo) CreateAuditLog(ctx context.Context, audit *domain.AttendanceAuditLog) error {
+	r.audits = append(r.audits, *audit)
+	return nil
+}
+
+func (r *fakeSessionRepo) Transaction(ctx context.Context, fn func(repo repository.AttendanceRepository) error) error {
+	return fn(r)
+}
+
+func (r *fakeSessionRepo) GetStaleCheckIns(ctx context.Context, checkedInBefore time.Time) ([]domain.CheckInLog, error) {
+	var stale []domain.CheckInLog
+	for _, l := range r.logs {
+		if l.CheckOutAt == nil && l.CheckInAt.Before(checkedInBefore) {
+			stale = append(stale, l)
+		}
+	}
+	return stale, nil
+}
+
+func (r *fakeSessionRepo) CloseSession(ctx context.Context, log *domain.CheckInLog) (bool, error) {
+	if r.closedElsewhere[log.ID] {
+		return false, nil
+	}
+	for i := range r.logs {
+		if r.logs[i].ID == log.ID && r.logs[i].CheckOutAt == nil {
+			r.logs[i] = *log
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeSettingValues 指定した値だけを持つ SettingsRepository
+type fakeSettingValues map[string]interface{}
+
+func (s fakeSettingValues) GetByKey(ctx context.Context, key string) (*domain.Setting, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s fakeSettingValues) GetValue(ctx context.Context, key string, dest interface{}) error {
+	v, ok := s[key]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+func (s fakeSettingValues) SetValue(ctx context.Context, key string, value interface{}, updatedBy uint) error {
+	s[key] = value
+	return nil
+}
+
+// refreshedRange RefreshDays で再集計した範囲
+type refreshedRange struct {
+	userID   uint
+	from, to time.Time
+}
+
+// fakeDailyService RefreshDays の呼び出しを記録する DailyAttendanceService
+type fakeDailyService struct {
+	DailyAttendanceService
+	refreshed []refreshedRange
+}
+
+func (d *fakeDailyService) RefreshDays(ctx context.Context, tx repository.AttendanceRepository, userID uint, from, to time.Time) error {
+	d.refreshed = append(d.refreshed, refreshedRange{userID: userID, from: from, to: to})
+	return nil
+}
+
+// nopAchievementService 称号を1つも解除しない AchievementService
+type nopAchievementService struct {
+	AchievementService
+}
+
+func (nopAchievementService) CheckAndUnlock(ctx context.Context, userID uint) ([]domain.Achievement, error) {
+	return nil, nil
+}
+
+type nopRankingInvalidator struct{}
+
+func (nopRankingInvalidator) Invalidate(userIDs ...uint) {}
+
+type nopRankingUpdateNotifier struct{}
+
+func (nopRankingUpdateNotifier) Seed(ctx context.Context) error { return nil }
+
+func (nopRankingUpdateNotifier) NotifyChanges(ctx context.Context) error { return nil }
+
+func newRunningHub() *ws.Hub {
+	hub := ws.NewHub()
+	go hub.Run()
+	return hub
+}
+
+func newTestAttendanceService(repo *fakeSessionRepo, settings repository.SettingsRepository, daily *fakeDailyService) *attendanceService {
+	return NewAttendanceService(repo, settings, newRunningHub(), nopAchievementService{}, daily,
+		nopRankingInvalidator{}, nopRankingUpdateNotifier{}, time.UTC).(*attendanceService)
+}
+
+func TestAutoCheckOut_CapsDuration(t *testing.T) {
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	repo := &fakeSessionRepo{logs: []domain.CheckInLog{
+		{ID: 1, UserID: 1, CheckInAt: now.Add(-5 * time.Hour)},
+		{ID: 2, UserID: 2, CheckInAt: now.Add(-time.Hour)},
+	}}
+	daily := &fakeDailyService{}
+	s := newTestAttendanceService(repo, fakeSettingValues{"auto_checkout_minutes": 120}, daily)
+
+	closed, err := s.AutoCheckOut(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed != 1 {
+		t.Fatalf("closed = %d, want 1", closed)
+	}
+
+	got := repo.logs[0]
+	wantOut := got.CheckInAt.Add(2 * time.Hour)
+	if got.CheckOutAt == nil || !got.CheckOutAt.Equal(wantOut) {
+		t.Errorf("check_out_at = %v, want %v", got.CheckOutAt, wantOut)
+	}
+	if got.DurationMinutes == nil || *got.DurationMinutes != 120 {
+		t.Errorf("duration_minutes = %v, want 120", got.DurationMinutes)
+	}
+	if !got.IsAutoCheckout {
+		t.Error("is_auto_checkout = false")
+	}
+	if repo.logs[1].CheckOutAt != nil {
+		t.Error("session within the limit was closed")
+	}
+	want := refreshedRange{userID: 1, from: got.CheckInAt, to: wantOut}
+	if len(daily.refreshed) != 1 || daily.refreshed[0] != want {
+		t.Errorf("refreshed = %v, want [%v]", daily.refreshed, want)
+	}
+}
+
+func TestAutoCheckOut_DefaultLimit(t *testing.T) {
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		settings fakeSettingValues
+	}{
+		{"未設定", fakeSettingValues{}},
+		{"0分", fakeSettingValues{"auto_checkout_minutes": 0}},
+		{"数値でない", fakeSettingValues{"auto_checkout_minutes": "ten hours"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSessionRepo{logs: []domain.CheckInLog{
+				{ID: 1, UserID: 1, CheckInAt: now.Add(-11 * time.Hour)},
+				{ID: 2, UserID: 2, CheckInAt: now.Add(-9 * time.Hour)},
+			}}
+			s := newTestAttendanceService(repo, tt.settings, &fakeDailyService{})
+
+			closed, err := s.AutoCheckOut(context.Background(), now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if closed != 1 {
+				t.Fatalf("closed = %d, want 1", closed)
+			}
+			if d := repo.logs[0].DurationMinutes; d == nil || *d != defaultAutoCheckoutMinutes {
+				t.Errorf("duration_minutes = %v, want %d", d, defaultAutoCheckoutMinutes)
+			}
+		})
+	}
+}
+
+func TestAutoCheckOut_SkipsSessionClosedConcurrently(t *testing.T) {
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	repo := &fakeSessionRepo{
+		logs:            []domain.CheckInLog{{ID: 1, UserID: 1, CheckInAt: now.Add(-11 * time.Hour)}},
+		closedElsewhere: map[uint]bool{1: true},
+	}
+	daily := &fakeDailyService{}
+	s := newTestAttendanceService(repo, fakeSettingValues{}, daily)
+
+	closed, err := s.AutoCheckOut(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed != 0 {
+		t.Errorf("closed = %d, want 0", closed)
+	}
+	if len(daily.refreshed) != 0 {
+		t.Errorf("daily attendance refreshed for a session closed elsewhere: %v", daily.refreshed)
+	}
+}
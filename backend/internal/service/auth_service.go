@@ -64,6 +64,8 @@ func (s *AuthService) AuthenticateWithLDAP(username, password string) (*domain.U
 		Email:       s.getAttributeValue(attributes, "mail", ""),
 		Role:        "student", // デフォルトはstudent、必要に応じて変更
 		IsActive:    true,
+		// 出席データはデフォルトで研究室メンバーに公開
+		ProfileVisibility: domain.VisibilityMembers,
 	}
 
 	return user, nil
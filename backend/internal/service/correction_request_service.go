@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/ws"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCorrectionNotFound       = errors.New("correction request not found")
+	ErrCorrectionNotPending     = errors.New("correction request is not pending")
+	ErrCorrectionAlreadyPending = errors.New("a pending correction request already exists for this session")
+	ErrCannotReviewOwnRequest   = errors.New("cannot review own correction request")
+	ErrInvalidCorrectionStatus  = errors.New("invalid correction request status")
+	ErrCorrectionSessionDeleted = errors.New("the session to correct has been deleted")
+)
+
+// CorrectionRequestInput 訂正申請の内容
+// CheckInLogID を指定した場合は既存の記録の訂正、指定しない場合は記録漏れの追加になる
+type CorrectionRequestInput struct {
+	CheckInLogID *uint     `json:"check_in_log_id"`
+	CheckInAt    time.Time `json:"check_in_at" binding:"required"`
+	CheckOutAt   time.Time `json:"check_out_at" binding:"required"`
+	Reason       string    `json:"reason"`
+}
+
+// CorrectionRequestService 出席記録の訂正申請と承認フロー
+type CorrectionRequestService interface {
+	Submit(ctx context.Context, userID uint, input *CorrectionRequestInput) (*domain.AttendanceCorrectionRequest, error)
+	GetMyRequests(ctx context.Context, userID uint) ([]domain.AttendanceCorrectionRequest, error)
+	GetRequestsByStatus(ctx context.Context, status string) ([]domain.AttendanceCorrectionRequest, error)
+	Approve(ctx context.Context, reviewerID uint, requestID uint, comment string) (*domain.AttendanceCorrectionRequest, error)
+	Reject(ctx context.Context, reviewerID uint, requestID uint, comment string) (*domain.AttendanceCorrectionRequest, error)
+}
+
+type correctionRequestService struct {
+	repo         repository.CorrectionRequestRepository
+	logRepo      repository.AttendanceRepository
+	adminService AttendanceAdminService
+	hub          *ws.Hub
+}
+
+func NewCorrectionRequestService(repo repository.CorrectionRequestRepository, logRepo repository.AttendanceRepository, adminService AttendanceAdminService, hub *ws.Hub) CorrectionRequestService {
+	return &correctionRequestService{
+		repo:         repo,
+		logRepo:      logRepo,
+		adminService: adminService,
+		hub:          hub,
+	}
+}
+
+func (s *correctionRequestService) Submit(ctx context.Context, userID uint, input *CorrectionRequestInput) (*domain.AttendanceCorrectionRequest, error) {
+	if err := validateSessionInput(&SessionInput{
+		CheckInAt:  input.CheckInAt,
+		CheckOutAt: input.CheckOutAt,
+		Reason:     input.Reason,
+	}, time.Now()); err != nil {
+		return nil, err
+	}
+
+	req := &domain.AttendanceCorrectionRequest{
+		UserID:      userID,
+		RequestType: domain.CorrectionTypeMissing,
+		CheckInAt:   input.CheckInAt,
+		CheckOutAt:  input.CheckOutAt,
+		Reason:      strings.TrimSpace(input.Reason),
+		Status:      domain.CorrectionStatusPending,
+	}
+
+	if input.CheckInLogID != nil {
+		// 自分の記録のみ訂正を申請できる
+		log, err := findSession(ctx, s.logRepo, *input.CheckInLogID)
+		if err != nil {
+			return nil, err
+		}
+		if log.UserID != userID {
+			return nil, ErrSessionNotFound
+		}
+		pending, err := s.repo.HasPendingForLog(ctx, log.ID)
+		if err != nil {
+			return nil, err
+		}
+		if pending {
+			return nil, ErrCorrectionAlreadyPending
+		}
+		req.RequestType = domain.CorrectionTypeModify
+		req.CheckInLogID = &log.ID
+	}
+
+	if err := s.repo.Create(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (s *correctionRequestService) GetMyRequests(ctx context.Context, userID uint) ([]domain.AttendanceCorrectionRequest, error) {
+	return s.repo.FindByUser(ctx, userID)
+}
+
+func (s *correctionRequestService) GetRequestsByStatus(ctx context.Context, status string) ([]domain.AttendanceCorrectionRequest, error) {
+	switch status {
+	case domain.CorrectionStatusPending, domain.CorrectionStatusApproved, domain.CorrectionStatusRejected:
+		return s.repo.FindByStatus(ctx, status)
+	}
+	return nil, fmt.Errorf("%s: %w", status, ErrInvalidCorrectionStatus)
+}
+
+// Approve 申請を承認し、申請内容どおりに出席記録を作成・修正する。
+// 出席記録の変更は修正履歴に「訂正申請#ID」として記録される。
+// 申請の行をロックし、出席記録の変更と申請の状態の更新を1つのトランザクションで行うため、
+// 同時に承認されても出席記録が二重に変更されることはない
+func (s *correctionRequestService) Approve(ctx context.Context, reviewerID uint, requestID uint, comment string) (*domain.AttendanceCorrectionRequest, error) {
+	var req *domain.AttendanceCorrectionRequest
+	var change *SessionChange
+	err := s.repo.Transaction(ctx, func(tx repository.CorrectionRequestRepository, logTx repository.AttendanceRepository) error {
+		var err error
+		req, err = findPending(ctx, tx, reviewerID, requestID)
+		if err != nil {
+			return err
+		}
+
+		input := &SessionInput{
+			UserID:     req.UserID,
+			CheckInAt:  req.CheckInAt,
+			CheckOutAt: req.CheckOutAt,
+			Reason:     fmt.Sprintf("訂正申請#%d: %s", req.ID, req.Reason),
+		}
+		var logID uint
+		if req.RequestType == domain.CorrectionTypeModify {
+			// 対象の記録が削除されると check_in_log_id は NULL になる。新しい記録として作成すると
+			// 申請者が求めていない出席を追加することになるため、承認できない
+			if req.CheckInLogID == nil {
+				return ErrCorrectionSessionDeleted
+			}
+			logID = *req.CheckInLogID
+		}
+		change, err = s.adminService.ApplySession(ctx, logTx, reviewerID, logID, input)
+		if err != nil {
+			return err
+		}
+
+		req.CheckInLogID = &change.After.ID
+		return decide(ctx, tx, req, reviewerID, domain.CorrectionStatusApproved, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.adminService.SessionApplied(ctx, change)
+	s.notifyDecided(req)
+	return req, nil
+}
+
+func (s *correctionRequestService) Reject(ctx context.Context, reviewerID uint, requestID uint, comment string) (*domain.AttendanceCorrectionRequest, error) {
+	var req *domain.AttendanceCorrectionRequest
+	err := s.repo.Transaction(ctx, func(tx repository.CorrectionRequestRepository, _ repository.AttendanceRepository) error {
+		var err error
+		req, err = findPending(ctx, tx, reviewerID, requestID)
+		if err != nil {
+			return err
+		}
+		return decide(ctx, tx, req, reviewerID, domain.CorrectionStatusRejected, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyDecided(req)
+	return req, nil
+}
+
+// findPending 承認待ちの申請を、承認・却下が終わるまで他から変更されないようロックして取得する
+func findPending(ctx context.Context, repo repository.CorrectionRequestRepository, reviewerID uint, requestID uint) (*domain.AttendanceCorrectionRequest, error) {
+	req, err := repo.FindByIDForUpdate(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCorrectionNotFound
+		}
+		return nil, err
+	}
+	if req.Status != domain.CorrectionStatusPending {
+		return nil, ErrCorrectionNotPending
+	}
+	if req.UserID == reviewerID {
+		return nil, ErrCannotReviewOwnRequest
+	}
+	return req, nil
+}
+
+// decide 承認・却下の結果を保存する
+func decide(ctx context.Context, repo repository.CorrectionRequestRepository, req *domain.AttendanceCorrectionRequest, reviewerID uint, status string, comment string) error {
+	now := time.Now()
+	req.Status = status
+	req.ReviewerID = &reviewerID
+	req.ReviewComment = strings.TrimSpace(comment)
+	req.ReviewedAt = &now
+	return repo.Update(ctx, req)
+}
+
+// notifyDecided 承認・却下の結果を申請者に WebSocket で通知する
+func (s *correctionRequestService) notifyDecided(req *domain.AttendanceCorrectionRequest) {
+	s.hub.SendToUser(req.UserID, map[string]interface{}{
+		"type":    "correction_decided",
+		"payload": req,
+	})
+}
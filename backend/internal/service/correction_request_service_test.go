@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeCorrectionRepo 申請をメモリ上に持つ CorrectionRequestRepository。
+// 行ロックの代わりにトランザクション全体を直列に実行する
+type fakeCorrectionRepo struct {
+	repository.CorrectionRequestRepository
+	mu   sync.Mutex
+	reqs map[uint]domain.AttendanceCorrectionRequest
+	logs *fakeSessionRepo
+}
+
+func (r *fakeCorrectionRepo) Transaction(ctx context.Context, fn func(repo repository.CorrectionRequestRepository, logRepo repository.AttendanceRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fn(r, r.logs)
+}
+
+func (r *fakeCorrectionRepo) FindByIDForUpdate(ctx context.Context, id uint) (*domain.AttendanceCorrectionRequest, error) {
+	req, ok := r.reqs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &req, nil
+}
+
+func (r *fakeCorrectionRepo) Update(ctx context.Context, req *domain.AttendanceCorrectionRequest) error {
+	r.reqs[req.ID] = *req
+	return nil
+}
+
+func newCorrectionFixture(req domain.AttendanceCorrectionRequest, logs ...domain.CheckInLog) (CorrectionRequestService, *fakeCorrectionRepo) {
+	admin := newAdminServiceFixture(logs...)
+	repo := &fakeCorrectionRepo{
+		reqs: map[uint]domain.AttendanceCorrectionRequest{req.ID: req},
+		logs: admin.repo,
+	}
+	return NewCorrectionRequestService(repo, admin.repo, admin.service, newRunningHub()), repo
+}
+
+func TestCorrectionRequestService_Approve(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 5, 1, hour, 0, 0, 0, time.UTC) }
+	logID := uint(1)
+	s, repo := newCorrectionFixture(domain.AttendanceCorrectionRequest{
+		ID: 5, UserID: 1, CheckInLogID: &logID, RequestType: domain.CorrectionTypeModify,
+		CheckInAt: at(9), CheckOutAt: at(17), Reason: "チェックアウト忘れ", Status: domain.CorrectionStatusPending,
+	}, domain.CheckInLog{ID: 1, UserID: 1, CheckInAt: at(9)})
+
+	// 自分の申請は承認できない
+	if _, err := s.Approve(context.Background(), 1, 5, ""); !errors.Is(err, ErrCannotReviewOwnRequest) {
+		t.Fatalf("err = %v, want %v", err, ErrCannotReviewOwnRequest)
+	}
+
+	req, err := s.Approve(context.Background(), 9, 5, "確認しました")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Status != domain.CorrectionStatusApproved || req.ReviewerID == nil || *req.ReviewerID != 9 || req.ReviewedAt == nil {
+		t.Errorf("req = %+v", req)
+	}
+	if stored := repo.reqs[5]; stored.Status != domain.CorrectionStatusApproved {
+		t.Errorf("stored status = %s", stored.Status)
+	}
+	session := repo.logs.logs[0]
+	if session.CheckOutAt == nil || !session.CheckOutAt.Equal(at(17)) {
+		t.Errorf("session = %+v", session)
+	}
+	if len(repo.logs.audits) != 1 || repo.logs.audits[0].Reason != "訂正申請#5: チェックアウト忘れ" {
+		t.Errorf("audits = %+v", repo.logs.audits)
+	}
+
+	if _, err := s.Approve(context.Background(), 9, 5, ""); !errors.Is(err, ErrCorrectionNotPending) {
+		t.Errorf("err = %v, want %v", err, ErrCorrectionNotPending)
+	}
+}
+
+func TestCorrectionRequestService_ApproveDeletedSession(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 5, 1, hour, 0, 0, 0, time.UTC) }
+	// 訂正対象の記録が削除され、check_in_log_id が NULL になった
+	s, repo := newCorrectionFixture(domain.AttendanceCorrectionRequest{
+		ID: 5, UserID: 1, RequestType: domain.CorrectionTypeModify,
+		CheckInAt: at(9), CheckOutAt: at(17), Reason: "チェックアウト忘れ", Status: domain.CorrectionStatusPending,
+	})
+
+	if _, err := s.Approve(context.Background(), 9, 5, ""); !errors.Is(err, ErrCorrectionSessionDeleted) {
+		t.Fatalf("err = %v, want %v", err, ErrCorrectionSessionDeleted)
+	}
+	if len(repo.logs.logs) != 0 || repo.reqs[5].Status != domain.CorrectionStatusPending {
+		t.Errorf("approval changed data: logs=%d status=%s", len(repo.logs.logs), repo.reqs[5].Status)
+	}
+}
+
+func TestCorrectionRequestService_Reject(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 5, 1, hour, 0, 0, 0, time.UTC) }
+	s, repo := newCorrectionFixture(domain.AttendanceCorrectionRequest{
+		ID: 5, UserID: 1, RequestType: domain.CorrectionTypeMissing,
+		CheckInAt: at(9), CheckOutAt: at(17), Reason: "記録漏れ", Status: domain.CorrectionStatusPending,
+	})
+
+	req, err := s.Reject(context.Background(), 9, 5, " 日付が違います ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Status != domain.CorrectionStatusRejected || req.ReviewComment != "日付が違います" {
+		t.Errorf("req = %+v", req)
+	}
+	if len(repo.logs.logs) != 0 || len(repo.logs.audits) != 0 {
+		t.Errorf("rejected request changed attendance: logs=%d audits=%d", len(repo.logs.logs), len(repo.logs.audits))
+	}
+	if _, err := s.Approve(context.Background(), 9, 5, ""); !errors.Is(err, ErrCorrectionNotPending) {
+		t.Errorf("err = %v, want %v", err, ErrCorrectionNotPending)
+	}
+}
+
+func TestCorrectionRequestService_ConcurrentApprove(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 5, 1, hour, 0, 0, 0, time.UTC) }
+	s, repo := newCorrectionFixture(domain.AttendanceCorrectionRequest{
+		ID: 5, UserID: 1, RequestType: domain.CorrectionTypeMissing,
+		CheckInAt: at(9), CheckOutAt: at(17), Reason: "記録漏れ", Status: domain.CorrectionStatusPending,
+	})
+
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.Approve(context.Background(), uint(8+i), 5, "")
+		}(i)
+	}
+	wg.Wait()
+
+	approved := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			approved++
+		case !errors.Is(err, ErrCorrectionNotPending):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if approved != 1 {
+		t.Errorf("approved %d time(s), want 1", approved)
+	}
+	if len(repo.logs.logs) != 1 {
+		t.Errorf("sessions created = %d, want 1", len(repo.logs.logs))
+	}
+}
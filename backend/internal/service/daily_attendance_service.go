@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+)
+
+// DailyAttendanceService daily_attendances（日次集計テーブル）を check_in_logs から作成する
+type DailyAttendanceService interface {
+	// RefreshDays は from〜to に掛かる各日の集計を再計算する。
+	// チェックアウト等と同じトランザクションで実行するため、トランザクション内のリポジトリを受け取る。
+	RefreshDays(ctx context.Context, tx repository.AttendanceRepository, userID uint, from, to time.Time) error
+	// Rebuild は期間内の全ユーザーの集計を作り直し、書き込んだ行数を返す
+	Rebuild(ctx context.Context, from, to time.Time) (int, error)
+}
+
+type dailyAttendanceService struct {
+	repo         repository.AttendanceRepository
+	settingsRepo repository.SettingsRepository
+	loc          *time.Location
+}
+
+func NewDailyAttendanceService(repo repository.AttendanceRepository, settingsRepo repository.SettingsRepository, loc *time.Location) DailyAttendanceService {
+	return &dailyAttendanceService{
+		repo:         repo,
+		settingsRepo: settingsRepo,
+		loc:          loc,
+	}
+}
+
+func (s *dailyAttendanceService) RefreshDays(ctx context.Context, tx repository.AttendanceRepository, userID uint, from, to time.Time) error {
+	start, end := s.dayRange(from, to)
+	logs, err := tx.GetClosedSessions(ctx, start, end, userID)
+	if err != nil {
+		return err
+	}
+	_, err = s.replace(ctx, tx, start, end, logs, userID)
+	return err
+}
+
+func (s *dailyAttendanceService) Rebuild(ctx context.Context, from, to time.Time) (int, error) {
+	start, end := s.dayRange(from, to)
+	var written int
+	err := s.repo.Transaction(ctx, func(tx repository.AttendanceRepository) error {
+		logs, err := tx.GetClosedSessions(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		written, err = s.replace(ctx, tx, start, end, logs)
+		return err
+	})
+	return written, err
+}
+
+// replace 期間 [start, end) の集計行を削除し、logs から計算し直した行を書き込む。
+// 期間外に掛かるセッションの他の日の分は、その日の他のセッションを含まないため書き込まない。
+func (s *dailyAttendanceService) replace(ctx context.Context, tx repository.AttendanceRepository, start, end time.Time, logs []domain.CheckInLog, userIDs ...uint) (int, error) {
+	holidays, err := loadHolidayCalendar(ctx, s.settingsRepo)
+	if err != nil {
+		return 0, err
+	}
+	pointRules, err := loadPointRules(ctx, s.settingsRepo)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.DeleteDailyAttendances(ctx, start, end, userIDs...); err != nil {
+		return 0, err
+	}
+	written := 0
+	for _, daily := range buildDailyAttendances(logs, s.loc, holidays) {
+		if daily.AttendanceDate.Before(start) || !daily.AttendanceDate.Before(end) {
+			continue
+		}
+		daily := daily
+		daily.Points = pointRules.pointsFor(daily)
+		if err := tx.UpsertDailyAttendance(ctx, &daily); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// dayRange from〜to を含む日単位の範囲 [start, end) を返す
+func (s *dailyAttendanceService) dayRange(from, to time.Time) (time.Time, time.Time) {
+	return startOfDay(from, s.loc), startOfDay(to, s.loc).AddDate(0, 0, 1)
+}
+
+// startOfDay t が属する日の 0:00（loc 基準）
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// daySlice セッションのうち1日分の滞在
+type daySlice struct {
+	date    time.Time // その日の 0:00（loc 基準）
+	minutes int
+}
+
+// splitByDay セッションを loc の暦日ごとに分割する。
+// 日をまたぐ滞在は各日の実際の滞在時間で按分し、合計が durationMinutes と一致するよう最終日で端数を調整する。
+func splitByDay(checkIn, checkOut time.Time, durationMinutes int, loc *time.Location) []daySlice {
+	checkIn, checkOut = checkIn.In(loc), checkOut.In(loc)
+
+	var slices []daySlice
+	allocated := 0
+	for day := startOfDay(checkIn, loc); day.Before(checkOut); day = day.AddDate(0, 0, 1) {
+		start, end := checkIn, checkOut
+		if day.After(start) {
+			start = day
+		}
+		if next := day.AddDate(0, 0, 1); next.Before(end) {
+			end = next
+		}
+		minutes := int(end.Sub(start).Minutes())
+		slices = append(slices, daySlice{date: day, minutes: minutes})
+		allocated += minutes
+	}
+
+	if len(slices) == 0 {
+		return []daySlice{{date: startOfDay(checkIn, loc), minutes: durationMinutes}}
+	}
+	last := &slices[len(slices)-1]
+	last.minutes += durationMinutes - allocated
+	if last.minutes < 0 {
+		last.minutes = 0
+	}
+	return slices
+}
+
+// buildDailyAttendances チェックアウト済みのセッションをユーザー・日付（loc 基準）ごとに集計する。
+// 日をまたぐセッションの滞在時間は各日に按分する。チェックイン回数・最初のチェックイン時刻は
+// チェックインした日に、最後のチェックアウト時刻はチェックアウトした日に計上する。
+// holidays に含まれる日は休日フラグを立てる。
+func buildDailyAttendances(logs []domain.CheckInLog, loc *time.Location, holidays holidayCalendar) []domain.DailyAttendance {
+	type key struct {
+		userID uint
+		date   time.Time
+	}
+	byDay := make(map[key]*domain.DailyAttendance)
+	dailyOf := func(userID uint, date time.Time) *domain.DailyAttendance {
+		k := key{userID: userID, date: date}
+		daily, ok := byDay[k]
+		if !ok {
+			daily = &domain.DailyAttendance{
+				UserID:         userID,
+				AttendanceDate: date,
+				IsHoliday:      holidays.IsHoliday(date),
+			}
+			byDay[k] = daily
+		}
+		return daily
+	}
+
+	for _, l := range logs {
+		if l.CheckOutAt == nil {
+			continue
+		}
+		checkIn := l.CheckInAt.In(loc)
+		checkOut := l.CheckOutAt.In(loc)
+		duration := int(checkOut.Sub(checkIn).Minutes())
+		if l.DurationMinutes != nil {
+			duration = *l.DurationMinutes
+		}
+
+		for _, slice := range splitByDay(checkIn, checkOut, duration, loc) {
+			dailyOf(l.UserID, slice.date).TotalDurationMinutes += slice.minutes
+		}
+
+		first := dailyOf(l.UserID, startOfDay(checkIn, loc))
+		first.CheckInCount++
+		if first.FirstCheckInAt == nil || checkIn.Before(*first.FirstCheckInAt) {
+			first.FirstCheckInAt = &checkIn
+		}
+
+		// 0:00 ちょうどのチェックアウトは前日の滞在の終わりとして扱う
+		lastDay := startOfDay(checkOut, loc)
+		if lastDay.Equal(checkOut) && checkOut.After(checkIn) {
+			lastDay = lastDay.AddDate(0, 0, -1)
+		}
+		last := dailyOf(l.UserID, lastDay)
+		if last.LastCheckOutAt == nil || checkOut.After(*last.LastCheckOutAt) {
+			last.LastCheckOutAt = &checkOut
+		}
+	}
+
+	dailies := make([]domain.DailyAttendance, 0, len(byDay))
+	for _, daily := range byDay {
+		dailies = append(dailies, *daily)
+	}
+	sort.Slice(dailies, func(i, j int) bool {
+		if dailies[i].UserID != dailies[j].UserID {
+			return dailies[i].UserID < dailies[j].UserID
+		}
+		return dailies[i].AttendanceDate.Before(dailies[j].AttendanceDate)
+	})
+	return dailies
+}
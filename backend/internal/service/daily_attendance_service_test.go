@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+)
+
+// dailyKey 日次集計の行を特定するキー
+type dailyKey struct {
+	userID uint
+	date   string
+}
+
+// fakeDailyRepo セッションと日次集計をメモリ上に持つ AttendanceRepository（集計で使うメソッドのみ）
+type fakeDailyRepo struct {
+	repository.AttendanceRepository
+	sessions []domain.CheckInLog
+	dailies  map[dailyKey]domain.DailyAttendance
+}
+
+func newFakeDailyRepo(sessions ...domain.CheckInLog) *fakeDailyRepo {
+	return &fakeDailyRepo{sessions: sessions, dailies: make(map[dailyKey]domain.DailyAttendance)}
+}
+
+func (r *fakeDailyRepo) Transaction(ctx context.Context, fn func(repo repository.AttendanceRepository) error) error {
+	return fn(r)
+}
+
+func (r *fakeDailyRepo) GetClosedSessions(ctx context.Context, from, to time.Time, userIDs ...uint) ([]domain.CheckInLog, error) {
+	var logs []domain.CheckInLog
+	for _, l := range r.sessions {
+		if l.CheckOutAt == nil || !l.CheckInAt.Before(to) || !l.CheckOutAt.After(from) || !matchesUserIDs(userIDs, l.UserID) {
+			continue
+		}
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
+
+func (r *fakeDailyRepo) DeleteDailyAttendances(ctx context.Context, from, to time.Time, userIDs ...uint) error {
+	for k := range r.dailies {
+		if k.date >= from.Format("2006-01-02") && k.date < to.Format("2006-01-02") && matchesUserIDs(userIDs, k.userID) {
+			delete(r.dailies, k)
+		}
+	}
+	return nil
+}
+
+func (r *fakeDailyRepo) UpsertDailyAttendance(ctx context.Context, daily *domain.DailyAttendance) error {
+	r.dailies[dailyKey{userID: daily.UserID, date: daily.AttendanceDate.Format("2006-01-02")}] = *daily
+	return nil
+}
+
+// matchesUserIDs userIDs が空（全ユーザー）か userID を含むか
+func matchesUserIDs(userIDs []uint, userID uint) bool {
+	return len(userIDs) == 0 || containsUserID(userIDs, userID)
+}
+
+func TestDailyAttendanceService_RebuildSplitsAtLabMidnight(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	at := func(d, hour int) time.Time { return time.Date(2026, 5, d, hour, 0, 0, 0, tokyo) }
+	// UTC では同じ日（5/1 13:00〜17:00）だが、研究室の時刻では日をまたぐ
+	repo := newFakeDailyRepo(closedLog(1, at(1, 22).UTC(), at(2, 2).UTC()))
+	s := NewDailyAttendanceService(repo, fakeSettingValues{}, tokyo)
+
+	written, err := s.Rebuild(context.Background(), at(1, 0), at(2, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 2 {
+		t.Fatalf("written = %d, want 2", written)
+	}
+	first := repo.dailies[dailyKey{1, "2026-05-01"}]
+	if first.TotalDurationMinutes != 120 || first.CheckInCount != 1 || first.LastCheckOutAt != nil {
+		t.Errorf("5/1 = %+v", first)
+	}
+	second := repo.dailies[dailyKey{1, "2026-05-02"}]
+	if second.TotalDurationMinutes != 120 || second.CheckInCount != 0 || second.LastCheckOutAt == nil {
+		t.Errorf("5/2 = %+v", second)
+	}
+}
+
+func TestDailyAttendanceService_RefreshDaysKeepsOtherDays(t *testing.T) {
+	loc := time.UTC
+	at := func(d, hour int) time.Time { return time.Date(2026, 5, d, hour, 0, 0, 0, loc) }
+	repo := newFakeDailyRepo(
+		// 前日から日をまたぐセッション（5/1 の分は範囲外）
+		closedLog(1, at(1, 22), at(2, 1)),
+		closedLog(1, at(2, 10), at(2, 12)),
+		closedLog(2, at(2, 10), at(2, 11)),
+	)
+	// 範囲外の日と他のユーザーの行は、古い値のままでも書き換えない
+	repo.dailies[dailyKey{1, "2026-05-01"}] = domain.DailyAttendance{UserID: 1, AttendanceDate: at(1, 0), TotalDurationMinutes: 999}
+	repo.dailies[dailyKey{1, "2026-05-03"}] = domain.DailyAttendance{UserID: 1, AttendanceDate: at(3, 0), TotalDurationMinutes: 999}
+	repo.dailies[dailyKey{2, "2026-05-02"}] = domain.DailyAttendance{UserID: 2, AttendanceDate: at(2, 0), TotalDurationMinutes: 999}
+	s := NewDailyAttendanceService(repo, fakeSettingValues{}, loc)
+
+	if err := s.RefreshDays(context.Background(), repo, 1, at(2, 10), at(2, 12)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := repo.dailies[dailyKey{1, "2026-05-02"}]; got.TotalDurationMinutes != 180 || got.CheckInCount != 1 {
+		t.Errorf("5/2 = %+v, want 180 minutes and 1 check-in", got)
+	}
+	for _, k := range []dailyKey{{1, "2026-05-01"}, {1, "2026-05-03"}, {2, "2026-05-02"}} {
+		if got := repo.dailies[k]; got.TotalDurationMinutes != 999 {
+			t.Errorf("%v was rewritten: %+v", k, got)
+		}
+	}
+}
+
+func TestDailyAttendanceService_HolidayFlags(t *testing.T) {
+	loc := time.UTC
+	at := func(d, hour int) time.Time { return time.Date(2026, 5, d, hour, 0, 0, 0, loc) }
+	repo := newFakeDailyRepo(
+		closedLog(1, at(3, 10), at(3, 12)),
+		closedLog(1, at(4, 10), at(4, 12)),
+	)
+	settings := fakeSettingValues{
+		settingHolidays:   []string{"2026-05-03"},
+		settingPointRules: PointRules{DailyPoints: 10, HolidayMultiplier: 2},
+	}
+	s := NewDailyAttendanceService(repo, settings, loc)
+
+	if _, err := s.Rebuild(context.Background(), at(3, 0), at(4, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.dailies[dailyKey{1, "2026-05-03"}]; !got.IsHoliday || got.Points != 20 {
+		t.Errorf("5/3 = %+v, want holiday with 20 points", got)
+	}
+	if got := repo.dailies[dailyKey{1, "2026-05-04"}]; got.IsHoliday || got.Points != 10 {
+		t.Errorf("5/4 = %+v, want weekday with 10 points", got)
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidHoliday = errors.New("invalid holiday")
+
+const (
+	// settingHolidays 休日リスト（"YYYY-MM-DD" の配列）
+	settingHolidays = "holidays"
+	// settingHolidayRules 休日・週末の扱い
+	settingHolidayRules = "holiday_rules"
+)
+
+// HolidayRules holiday_rules 設定
+type HolidayRules struct {
+	// StreakSkipsHolidays 休日に来なくても連続出席が途切れない
+	StreakSkipsHolidays bool `json:"streak_skips_holidays"`
+	// StreakSkipsWeekends 土日に来なくても連続出席が途切れない
+	StreakSkipsWeekends bool `json:"streak_skips_weekends"`
+	// RankingExcludesHolidays ランキングで休日の滞在時間を数えない
+	RankingExcludesHolidays bool `json:"ranking_excludes_holidays"`
+}
+
+// holidayCalendar 休日の集合（"YYYY-MM-DD"）
+type holidayCalendar map[string]bool
+
+// IsHoliday date（loc 基準の日付）が休日か
+func (c holidayCalendar) IsHoliday(date time.Time) bool {
+	return c[date.Format("2006-01-02")]
+}
+
+// isRestDay 連続出席の判定で飛ばしてよい日か
+func (c holidayCalendar) isRestDay(rules HolidayRules) func(time.Time) bool {
+	return func(date time.Time) bool {
+		if rules.StreakSkipsWeekends {
+			switch date.Weekday() {
+			case time.Saturday, time.Sunday:
+				return true
+			}
+		}
+		return rules.StreakSkipsHolidays && c.IsHoliday(date)
+	}
+}
+
+// loadHolidayCalendar holidays 設定を読み込む（設定が無い場合は休日なし）
+func loadHolidayCalendar(ctx context.Context, settingsRepo repository.SettingsRepository) (holidayCalendar, error) {
+	dates, err := loadHolidayDates(ctx, settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+	calendar := make(holidayCalendar, len(dates))
+	for _, d := range dates {
+		calendar[d] = true
+	}
+	return calendar, nil
+}
+
+func loadHolidayDates(ctx context.Context, settingsRepo repository.SettingsRepository) ([]string, error) {
+	var dates []string
+	if err := settingsRepo.GetValue(ctx, settingHolidays, &dates); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%s: %w", settingHolidays, err)
+	}
+	return dates, nil
+}
+
+// loadHolidayRules holiday_rules 設定を読み込む（設定が無い場合は全て無効）
+func loadHolidayRules(ctx context.Context, settingsRepo repository.SettingsRepository) (HolidayRules, error) {
+	var rules HolidayRules
+	if err := settingsRepo.GetValue(ctx, settingHolidayRules, &rules); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return rules, fmt.Errorf("%s: %w", settingHolidayRules, err)
+	}
+	return rules, nil
+}
+
+// academicYearRange 年度（4月1日〜翌年3月31日）の範囲 [start, end)
+func academicYearRange(year int, loc *time.Location) (time.Time, time.Time) {
+	start := time.Date(year, time.April, 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(1, 0, 0)
+}
+
+// HolidayService 年度ごとの休日カレンダーの管理
+type HolidayService interface {
+	GetHolidays(ctx context.Context, academicYear int) ([]string, error)
+	// SetHolidays は年度内の休日を dates で置き換え、その年度の日次集計の休日フラグを付け直す
+	SetHolidays(ctx context.Context, actorID uint, academicYear int, dates []string) ([]string, error)
+	// ImportICal は iCalendar ファイルの終日イベントを休日に追加する。apply が false の場合は差分の確認のみ行う
+	ImportICal(ctx context.Context, actorID uint, r io.Reader, apply bool) (*HolidayImportResult, error)
+}
+
+// HolidayImportResult iCalendar 取り込みの差分
+type HolidayImportResult struct {
+	// Added 新たに休日になる日
+	Added []ImportedHoliday `json:"added"`
+	// AlreadyRegistered 既に休日として登録済みの日数
+	AlreadyRegistered int `json:"already_registered"`
+	// SkippedEvents 時刻付きのため取り込まなかったイベント数
+	SkippedEvents int  `json:"skipped_events"`
+	Applied       bool `json:"applied"`
+}
+
+type holidayService struct {
+	settingsRepo repository.SettingsRepository
+	dailyService DailyAttendanceService
+	rankings     RankingInvalidator // CLI など、ランキングのキャッシュが無い場合は nil
+	loc          *time.Location
+}
+
+func NewHolidayService(settingsRepo repository.SettingsRepository, dailyService DailyAttendanceService, rankings RankingInvalidator, loc *time.Location) HolidayService {
+	return &holidayService{
+		settingsRepo: settingsRepo,
+		dailyService: dailyService,
+		rankings:     rankings,
+		loc:          loc,
+	}
+}
+
+func (s *holidayService) GetHolidays(ctx context.Context, academicYear int) ([]string, error) {
+	dates, err := loadHolidayDates(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+	start, end := academicYearRange(academicYear, s.loc)
+	holidays := []string{}
+	for _, d := range dates {
+		if date, err := time.ParseInLocation("2006-01-02", d, s.loc); err == nil && !date.Before(start) && date.Before(end) {
+			holidays = append(holidays, d)
+		}
+	}
+	sort.Strings(holidays)
+	return holidays, nil
+}
+
+func (s *holidayService) SetHolidays(ctx context.Context, actorID uint, academicYear int, dates []string) ([]string, error) {
+	start, end := academicYearRange(academicYear, s.loc)
+
+	// 入力の検証と重複除去
+	holidays := make([]string, 0, len(dates))
+	seen := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		date, err := time.ParseInLocation("2006-01-02", d, s.loc)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", d, ErrInvalidHoliday)
+		}
+		if date.Before(start) || !date.Before(end) {
+			return nil, fmt.Errorf("%s is not in academic year %d: %w", d, academicYear, ErrInvalidHoliday)
+		}
+		if !seen[d] {
+			seen[d] = true
+			holidays = append(holidays, d)
+		}
+	}
+
+	// 他の年度の休日はそのまま残す
+	current, err := loadHolidayDates(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+	all := append([]string{}, holidays...)
+	for _, d := range current {
+		date, err := time.ParseInLocation("2006-01-02", d, s.loc)
+		if err == nil && !date.Before(start) && date.Before(end) {
+			continue
+		}
+		all = append(all, d)
+	}
+	sort.Strings(all)
+	sort.Strings(holidays)
+
+	if err := s.settingsRepo.SetValue(ctx, settingHolidays, all, actorID); err != nil {
+		return nil, err
+	}
+	if err := s.refreshDailyAttendances(ctx, start, end.AddDate(0, 0, -1)); err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+func (s *holidayService) ImportICal(ctx context.Context, actorID uint, r io.Reader, apply bool) (*HolidayImportResult, error) {
+	imported, skipped, err := parseICalHolidays(r)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := loadHolidayDates(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
+	registered := make(map[string]bool, len(current))
+	for _, d := range current {
+		registered[d] = true
+	}
+
+	result := &HolidayImportResult{Added: []ImportedHoliday{}, SkippedEvents: skipped}
+	added := make(map[string]bool)
+	for _, h := range imported {
+		switch {
+		case registered[h.Date]:
+			result.AlreadyRegistered++
+		case added[h.Date]:
+			// 同じ日の複数のイベントは最初のものだけを差分に出す
+		default:
+			added[h.Date] = true
+			result.Added = append(result.Added, h)
+		}
+	}
+	sort.Slice(result.Added, func(i, j int) bool { return result.Added[i].Date < result.Added[j].Date })
+
+	if !apply || len(result.Added) == 0 {
+		return result, nil
+	}
+
+	all := append([]string{}, current...)
+	for _, h := range result.Added {
+		all = append(all, h.Date)
+	}
+	sort.Strings(all)
+	if err := s.settingsRepo.SetValue(ctx, settingHolidays, all, actorID); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+
+	first, _ := time.ParseInLocation("2006-01-02", result.Added[0].Date, s.loc)
+	last, _ := time.ParseInLocation("2006-01-02", result.Added[len(result.Added)-1].Date, s.loc)
+	if err := s.refreshDailyAttendances(ctx, first, last); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// refreshDailyAttendances 休日の変更を from〜to（今日まで）の日次集計の休日フラグに反映する
+func (s *holidayService) refreshDailyAttendances(ctx context.Context, from, to time.Time) error {
+	today := startOfDay(time.Now(), s.loc)
+	if from.After(today) {
+		return nil
+	}
+	if to.After(today) {
+		to = today
+	}
+	if _, err := s.dailyService.Rebuild(ctx, from, to); err != nil {
+		return err
+	}
+	if s.rankings != nil {
+		s.rankings.Invalidate()
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLongestStreak_SkipsRestDays(t *testing.T) {
+	loc := time.UTC
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 0, 0, 0, 0, loc) }
+	// 2026-05-01(金) の後、土日と 05-04(月)・05-05(火) の休日を挟んで 05-06(水) から来る
+	holidays := holidayCalendar{"2026-05-04": true, "2026-05-05": true}
+	days := []time.Time{day(1), day(6), day(7)}
+
+	tests := []struct {
+		name  string
+		rules HolidayRules
+		want  int
+	}{
+		{"休みを考慮しない", HolidayRules{}, 2},
+		{"土日のみ", HolidayRules{StreakSkipsWeekends: true}, 2},
+		{"土日と休日", HolidayRules{StreakSkipsWeekends: true, StreakSkipsHolidays: true}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := longestStreak(days, holidays.isRestDay(tt.rules)); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var ErrInvalidICal = errors.New("invalid iCalendar file")
+
+// ImportedHoliday iCalendar の終日イベントから取り込む休日（1日分）
+type ImportedHoliday struct {
+	Date    string `json:"date"` // YYYY-MM-DD
+	Summary string `json:"summary"`
+}
+
+// parseICalHolidays iCalendar (RFC 5545) の終日イベントを日付ごとに展開して返す。
+// 時刻付きのイベントは休日ではないため読み飛ばし、その件数を skipped として返す。
+// 繰り返し（RRULE）は展開しない。
+func parseICalHolidays(r io.Reader) ([]ImportedHoliday, int, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		holidays []ImportedHoliday
+		skipped  int
+		inEvent  bool
+		start    string
+		end      string
+		allDay   bool
+		summary  string
+	)
+	for i, line := range lines {
+		name, value := splitICalProperty(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent, start, end, allDay, summary = true, "", "", false, ""
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if !inEvent {
+				return nil, 0, fmt.Errorf("line %d: unexpected END:VEVENT: %w", i+1, ErrInvalidICal)
+			}
+			inEvent = false
+			if !allDay {
+				skipped++
+				continue
+			}
+			days, err := expandICalDays(start, end)
+			if err != nil {
+				return nil, 0, fmt.Errorf("event %q: %w", summary, err)
+			}
+			for _, d := range days {
+				holidays = append(holidays, ImportedHoliday{Date: d, Summary: summary})
+			}
+		case !inEvent:
+			continue
+		case name == "DTSTART":
+			start = value
+			// 値が日付のみ（DTSTART;VALUE=DATE:YYYYMMDD）の場合が終日イベント
+			allDay = len(value) == len("20060102")
+		case name == "DTEND":
+			end = value
+		case name == "SUMMARY":
+			summary = unescapeICalText(value)
+		}
+	}
+	if inEvent {
+		return nil, 0, fmt.Errorf("missing END:VEVENT: %w", ErrInvalidICal)
+	}
+	return holidays, skipped, nil
+}
+
+// unfoldICalLines 折り返された行（空白・タブで始まる行）を前の行に連結する
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("missing BEGIN:VCALENDAR: %w", ErrInvalidICal)
+	}
+	return lines, nil
+}
+
+// splitICalProperty "NAME;PARAM=...:VALUE" を名前と値に分ける（パラメーターは使わない）
+func splitICalProperty(line string) (name, value string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), ""
+	}
+	name, value = line[:colon], line[colon+1:]
+	if semi := strings.Index(name, ";"); semi >= 0 {
+		name = name[:semi]
+	}
+	return strings.ToUpper(name), value
+}
+
+// expandICalDays 終日イベントの日付を列挙する（DTEND はその日を含まない。省略時は1日）
+func expandICalDays(start, end string) ([]string, error) {
+	from, err := time.Parse("20060102", start)
+	if err != nil {
+		return nil, fmt.Errorf("DTSTART %q: %w", start, ErrInvalidICal)
+	}
+	to := from.AddDate(0, 0, 1)
+	if end != "" {
+		if to, err = time.Parse("20060102", end); err != nil {
+			return nil, fmt.Errorf("DTEND %q: %w", end, ErrInvalidICal)
+		}
+		if !to.After(from) {
+			return nil, fmt.Errorf("DTEND %q is not after DTSTART %q: %w", end, start, ErrInvalidICal)
+		}
+	}
+	var days []string
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d.Format("2006-01-02"))
+	}
+	return days, nil
+}
+
+// unescapeICalText TEXT 値のエスケープ（\\ \; \, \n）を戻す
+func unescapeICalText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, " ", `\N`, " ").Replace(s)
+}
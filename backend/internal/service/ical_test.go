@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseICalHolidays(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20250503",
+		"DTEND;VALUE=DATE:20250506",
+		"SUMMARY:憲法記念日\\, みどりの日",
+		" \\, こどもの日",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20251225",
+		"SUMMARY:冬季休業",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20250415T090000Z",
+		"DTEND:20250415T100000Z",
+		"SUMMARY:ガイダンス",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	holidays, skipped, err := parseICalHolidays(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("読み込みに失敗しました: %v", err)
+	}
+	if skipped != 1 {
+		t.Errorf("時刻付きイベントの件数: got %d, want 1", skipped)
+	}
+
+	want := []ImportedHoliday{
+		{"2025-05-03", "憲法記念日, みどりの日, こどもの日"},
+		{"2025-05-04", "憲法記念日, みどりの日, こどもの日"},
+		{"2025-05-05", "憲法記念日, みどりの日, こどもの日"},
+		{"2025-12-25", "冬季休業"},
+	}
+	if len(holidays) != len(want) {
+		t.Fatalf("got %v, want %v", holidays, want)
+	}
+	for i := range want {
+		if holidays[i] != want[i] {
+			t.Errorf("%d: got %v, want %v", i, holidays[i], want[i])
+		}
+	}
+}
+
+func TestParseICalHolidays_Invalid(t *testing.T) {
+	for name, ics := range map[string]string{
+		"VCALENDARでない": "BEGIN:VEVENT\nEND:VEVENT\n",
+		"不正な日付":        "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:2025AB01\nEND:VEVENT\nEND:VCALENDAR\n",
+		"閉じていない":       "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20250101\n",
+		"終了が開始以前":      "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20250105\nDTEND;VALUE=DATE:20250105\nEND:VEVENT\nEND:VCALENDAR\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := parseICalHolidays(strings.NewReader(ics)); !errors.Is(err, ErrInvalidICal) {
+				t.Errorf("ErrInvalidICal になるべきです: got %v", err)
+			}
+		})
+	}
+}
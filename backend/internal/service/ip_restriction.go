@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+// ipRestriction allowed_ip_range 設定から作られる許可・拒否リスト
+//
+//	{"ips": ["133.38.201.125", "133.38.0.0/16", "2001:db8::/32"], "deny": ["133.38.201.0/28"]}
+//
+// ips / deny の要素には単一のアドレスまたはCIDR（IPv4/IPv6）を指定できる。
+// ips が無い場合は許可リストによる制限を行わず、deny のみを適用する。
+type ipRestriction struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+	// hasAllowList ips キーが設定されているか（空配列の場合は全て拒否になる）
+	hasAllowList bool
+}
+
+// parseIPRestriction 設定値から許可・拒否リストを作成する
+func parseIPRestriction(value domain.JSONB) (*ipRestriction, error) {
+	r := &ipRestriction{}
+
+	if raw, ok := value["ips"]; ok {
+		allow, err := parsePrefixList("ips", raw)
+		if err != nil {
+			return nil, err
+		}
+		r.allow = allow
+		r.hasAllowList = true
+	}
+	if raw, ok := value["deny"]; ok {
+		deny, err := parsePrefixList("deny", raw)
+		if err != nil {
+			return nil, err
+		}
+		r.deny = deny
+	}
+	return r, nil
+}
+
+func parsePrefixList(key string, raw interface{}) ([]netip.Prefix, error) {
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("allowed_ip_range.%s must be an array", key)
+	}
+	prefixes := make([]netip.Prefix, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("allowed_ip_range.%s: %v is not a string", key, item)
+		}
+		prefix, err := parsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("allowed_ip_range.%s: %w", key, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// parsePrefix "1.2.3.4" や "2001:db8::1" のような単一アドレスは /32, /128 として扱う
+func parsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Check クライアントIPがチェックイン可能か判定する。
+// 拒否リストを先に評価し、該当した場合は許可リストに含まれていても拒否する。
+// エラーメッセージには判定に使ったルールを含める。
+func (r *ipRestriction) Check(clientIP string) error {
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return fmt.Errorf("IPアドレスを判別できません (Your IP: %s, ルール: allowed_ip_range): %w", clientIP, ErrRestrictionViolation)
+	}
+	addr = addr.Unmap().WithZone("")
+
+	for _, prefix := range r.deny {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("このネットワークからはチェックインできません (Your IP: %s, 拒否ルール: allowed_ip_range.deny %s): %w", clientIP, prefix, ErrRestrictionViolation)
+		}
+	}
+
+	if !r.hasAllowList {
+		return nil
+	}
+	for _, prefix := range r.allow {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	return fmt.Errorf("研究室のWifiに接続してください (Your IP: %s, 許可ルール: allowed_ip_range.ips): %w", clientIP, ErrRestrictionViolation)
+}
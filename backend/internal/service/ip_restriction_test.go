@@ -0,0 +1,64 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+func TestIPRestriction_Check(t *testing.T) {
+	restriction, err := parseIPRestriction(domain.JSONB{
+		"ips":  []interface{}{"133.38.201.125", "10.0.0.0/8", "2001:db8::/32"},
+		"deny": []interface{}{"10.0.5.0/24"},
+	})
+	if err != nil {
+		t.Fatalf("設定の読み込みに失敗しました: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ip      string
+		allowed bool
+	}{
+		{"完全一致", "133.38.201.125", true},
+		{"CIDR内", "10.1.2.3", true},
+		{"拒否リストが優先", "10.0.5.10", false},
+		{"範囲外", "192.168.0.1", false},
+		{"IPv6 CIDR内", "2001:db8::1234", true},
+		{"IPv6 範囲外", "2001:db9::1", false},
+		{"IPv4射影アドレス", "::ffff:133.38.201.125", true},
+		{"不正なアドレス", "not-an-ip", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := restriction.Check(tt.ip)
+			if tt.allowed && err != nil {
+				t.Errorf("%s は許可されるべきです: %v", tt.ip, err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrRestrictionViolation) {
+				t.Errorf("%s は拒否されるべきです: got %v", tt.ip, err)
+			}
+		})
+	}
+}
+
+func TestIPRestriction_DenyOnly(t *testing.T) {
+	restriction, err := parseIPRestriction(domain.JSONB{"deny": []interface{}{"192.0.2.1"}})
+	if err != nil {
+		t.Fatalf("設定の読み込みに失敗しました: %v", err)
+	}
+	if err := restriction.Check("198.51.100.7"); err != nil {
+		t.Errorf("ips が無い場合は拒否リスト以外を許可するべきです: %v", err)
+	}
+	if err := restriction.Check("192.0.2.1"); !errors.Is(err, ErrRestrictionViolation) {
+		t.Errorf("拒否リストのアドレスは拒否されるべきです: got %v", err)
+	}
+}
+
+func TestParseIPRestriction_InvalidEntry(t *testing.T) {
+	if _, err := parseIPRestriction(domain.JSONB{"ips": []interface{}{"10.0.0.0/33"}}); err == nil {
+		t.Error("不正なCIDRはエラーになるべきです")
+	}
+}
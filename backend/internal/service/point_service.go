@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+// settingPointRules 出席ポイントの付与ルール
+const settingPointRules = "point_rules"
+
+// pointLedgerLimit 残高と一緒に返す台帳の件数
+const pointLedgerLimit = 100
+
+// PointRules point_rules 設定
+type PointRules struct {
+	// DailyPoints 1日の出席で得られるポイント
+	DailyPoints int `json:"daily_points"`
+	// MinMinutes 出席ポイントを得るのに必要な1日の滞在時間（分）
+	MinMinutes int `json:"min_minutes"`
+	// HolidayMultiplier 休日に出席した場合の倍率
+	HolidayMultiplier int `json:"holiday_multiplier"`
+}
+
+// defaultPointRules point_rules 設定が無い場合のルール（出席した日は1ポイント）
+var defaultPointRules = PointRules{DailyPoints: 1, MinMinutes: 0, HolidayMultiplier: 1}
+
+// pointsFor 日次集計1日分の出席ポイント
+func (r PointRules) pointsFor(daily domain.DailyAttendance) int {
+	if daily.TotalDurationMinutes < r.MinMinutes {
+		return 0
+	}
+	if daily.IsHoliday {
+		return r.DailyPoints * r.HolidayMultiplier
+	}
+	return r.DailyPoints
+}
+
+// loadPointRules point_rules 設定を読み込む（省略した項目は既定値）
+func loadPointRules(ctx context.Context, settingsRepo repository.SettingsRepository) (PointRules, error) {
+	rules := defaultPointRules
+	if err := settingsRepo.GetValue(ctx, settingPointRules, &rules); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return rules, fmt.Errorf("%s: %w", settingPointRules, err)
+	}
+	return rules, nil
+}
+
+// PointBalance ユーザーのポイント残高と台帳
+type PointBalance struct {
+	UserID  uint                      `json:"user_id"`
+	Balance int                       `json:"balance"`
+	Ledger  []domain.PointLedgerEntry `json:"ledger"`
+}
+
+type PointService interface {
+	GetBalance(ctx context.Context, userID uint) (*PointBalance, error)
+}
+
+type pointService struct {
+	repo repository.PointRepository
+}
+
+func NewPointService(repo repository.PointRepository) PointService {
+	return &pointService{repo: repo}
+}
+
+func (s *pointService) GetBalance(ctx context.Context, userID uint) (*PointBalance, error) {
+	balance, err := s.repo.GetBalance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ledger, err := s.repo.GetLedger(ctx, userID, pointLedgerLimit)
+	if err != nil {
+		return nil, err
+	}
+	if ledger == nil {
+		ledger = []domain.PointLedgerEntry{}
+	}
+	return &PointBalance{UserID: userID, Balance: balance, Ledger: ledger}, nil
+}
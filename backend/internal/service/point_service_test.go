@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+func TestPointRules_PointsFor(t *testing.T) {
+	rules := PointRules{DailyPoints: 10, MinMinutes: 60, HolidayMultiplier: 3}
+	tests := []struct {
+		name    string
+		minutes int
+		holiday bool
+		want    int
+	}{
+		{"滞在時間が足りない", 59, false, 0},
+		{"滞在時間がちょうど", 60, false, 10},
+		{"滞在時間が超える", 61, false, 10},
+		{"休日は倍率を掛ける", 60, true, 30},
+		{"休日でも滞在時間が足りなければ0", 59, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.pointsFor(domain.DailyAttendance{TotalDurationMinutes: tt.minutes, IsHoliday: tt.holiday})
+			if got != tt.want {
+				t.Errorf("pointsFor = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// 既定のルールでは出席した日は休日も含めて1ポイント
+	for _, holiday := range []bool{false, true} {
+		if got := defaultPointRules.pointsFor(domain.DailyAttendance{TotalDurationMinutes: 1, IsHoliday: holiday}); got != 1 {
+			t.Errorf("default rules (holiday=%v) = %d, want 1", holiday, got)
+		}
+	}
+}
+
+func TestLoadPointRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings fakeSettingValues
+		want     PointRules
+		wantErr  bool
+	}{
+		{"設定が無い場合は既定値", fakeSettingValues{}, defaultPointRules, false},
+		{
+			"省略した項目は既定値",
+			fakeSettingValues{settingPointRules: map[string]int{"daily_points": 10}},
+			PointRules{DailyPoints: 10, MinMinutes: 0, HolidayMultiplier: 1},
+			false,
+		},
+		{
+			"全ての項目を指定",
+			fakeSettingValues{settingPointRules: PointRules{DailyPoints: 5, MinMinutes: 30, HolidayMultiplier: 2}},
+			PointRules{DailyPoints: 5, MinMinutes: 30, HolidayMultiplier: 2},
+			false,
+		},
+		// 不正な設定で誤ったポイントを書き込まないよう、既定値にせずエラーにする
+		{"オブジェクトでない", fakeSettingValues{settingPointRules: "10 points"}, PointRules{}, true},
+		{"型が違う", fakeSettingValues{settingPointRules: json.RawMessage(`{"daily_points": "ten"}`)}, PointRules{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadPointRules(context.Background(), tt.settings)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), settingPointRules) {
+					t.Errorf("err = %v, want an error naming %s", err, settingPointRules)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("rules = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeLedgerRepo 日次の出席ポイントと fakeAwardRepo に記録したポイント取引から残高を計算する PointRepository
+// （point_ledger ビューと同じく、0ポイントの日は台帳に含めない）
+type fakeLedgerRepo struct {
+	*fakeAwardRepo
+	dailies []domain.DailyAttendance
+}
+
+func (r *fakeLedgerRepo) GetLedger(ctx context.Context, userID uint, limit int) ([]domain.PointLedgerEntry, error) {
+	var entries []domain.PointLedgerEntry
+	for _, d := range r.dailies {
+		if d.UserID != userID || d.Points == 0 {
+			continue
+		}
+		date := d.AttendanceDate
+		entries = append(entries, domain.PointLedgerEntry{UserID: userID, Source: domain.PointSourceAttendance, Amount: d.Points, AttendanceDate: &date, CreatedAt: d.UpdatedAt})
+	}
+	for _, tx := range r.records.points {
+		if tx.UserID != userID {
+			continue
+		}
+		entries = append(entries, domain.PointLedgerEntry{UserID: userID, Source: tx.Source, Amount: tx.Amount, AchievementID: tx.AchievementID, Description: tx.Description, CreatedAt: tx.CreatedAt})
+	}
+	at := func(e domain.PointLedgerEntry) time.Time {
+		if e.AttendanceDate != nil {
+			return *e.AttendanceDate
+		}
+		return e.CreatedAt
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return at(entries[i]).After(at(entries[j])) })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func (r *fakeLedgerRepo) GetBalance(ctx context.Context, userID uint) (int, error) {
+	entries, err := r.GetLedger(ctx, userID, len(r.dailies)+len(r.records.points))
+	if err != nil {
+		return 0, err
+	}
+	balance := 0
+	for _, e := range entries {
+		balance += e.Amount
+	}
+	return balance, nil
+}
+
+func TestPointService_BalanceIncludesAchievementRewards(t *testing.T) {
+	achievements, awardRepo := newAwardFixture()
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 0, 0, 0, 0, time.UTC) }
+	repo := &fakeLedgerRepo{fakeAwardRepo: awardRepo, dailies: []domain.DailyAttendance{
+		{UserID: 1, AttendanceDate: day(1), Points: 10},
+		{UserID: 1, AttendanceDate: day(2), Points: 0},
+		{UserID: 1, AttendanceDate: day(3), Points: 20},
+		{UserID: 2, AttendanceDate: day(3), Points: 99},
+	}}
+	s := NewPointService(repo)
+
+	before, err := s.GetBalance(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.Balance != 30 || len(before.Ledger) != 2 {
+		t.Fatalf("before award: balance = %d, ledger = %+v", before.Balance, before.Ledger)
+	}
+
+	// 称号の報酬（50ポイント）が出席ポイントに加算される
+	if _, err := achievements.Award(context.Background(), 9, 1, 1, "r"); err != nil {
+		t.Fatal(err)
+	}
+	after, err := s.GetBalance(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Balance != 80 {
+		t.Errorf("balance = %d, want 80", after.Balance)
+	}
+	var reward *domain.PointLedgerEntry
+	for i, e := range after.Ledger {
+		if e.Source == domain.PointSourceAchievement {
+			reward = &after.Ledger[i]
+		}
+	}
+	if reward == nil || reward.Amount != 50 || reward.AchievementID == nil || *reward.AchievementID != 1 {
+		t.Errorf("ledger = %+v, want an achievement reward of 50", after.Ledger)
+	}
+}
+
+func TestPointService_EmptyLedger(t *testing.T) {
+	_, awardRepo := newAwardFixture()
+	balance, err := NewPointService(&fakeLedgerRepo{fakeAwardRepo: awardRepo}).GetBalance(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Balance != 0 || balance.Ledger == nil || len(balance.Ledger) != 0 {
+		t.Errorf("balance = %+v, want 0 with an empty ledger", balance)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+// rankingCacheTTL キャッシュの有効期限。
+// CLI からの集計し直しや設定の変更など、無効化の通知が届かない変更もこの時間が経てば反映される
+const rankingCacheTTL = 10 * time.Minute
+
+// rankingCacheSize キャッシュする期間の数の上限
+const rankingCacheSize = 64
+
+// RankingInvalidator 出席記録やポイントが変わったときにランキングのキャッシュを無効にする
+type RankingInvalidator interface {
+	// Invalidate は userIDs の集計を次の取得時に再計算させる。userIDs を省略した場合は全て捨てる
+	Invalidate(userIDs ...uint)
+}
+
+// rankingBaseKey 集計の元データの種類と期間
+type rankingBaseKey struct {
+	points          bool
+	from, to        int64
+	excludeHolidays bool
+}
+
+// rankingBase 期間内の日次集計（またはポイント台帳）のユーザーごとの集計。
+// 在室中の滞在は含まないため、取得のたびに加える。作成後は変更しない
+type rankingBase struct {
+	rankings   map[uint]domain.UserRanking
+	days       map[uint][]time.Time // 出席日（ポイントの場合は無し）
+	computedAt time.Time
+}
+
+// update users の集計を rankings と days で置き換えた新しい rankingBase を返す
+func (b *rankingBase) update(users []uint, rankings []domain.UserRanking, days map[uint][]time.Time) *rankingBase {
+	updated := &rankingBase{
+		rankings:   make(map[uint]domain.UserRanking, len(b.rankings)),
+		days:       make(map[uint][]time.Time, len(b.days)),
+		computedAt: b.computedAt,
+	}
+	for id, r := range b.rankings {
+		updated.rankings[id] = r
+	}
+	for id, d := range b.days {
+		updated.days[id] = d
+	}
+	for _, id := range users {
+		delete(updated.rankings, id)
+		delete(updated.days, id)
+	}
+	for _, r := range rankings {
+		updated.rankings[r.UserID] = r
+	}
+	for id, d := range days {
+		updated.days[id] = d
+	}
+	return updated
+}
+
+type rankingCacheEntry struct {
+	base  *rankingBase
+	dirty map[uint]bool
+}
+
+// rankingCache 期間ごとの rankingBase のキャッシュ
+type rankingCache struct {
+	mu      sync.Mutex
+	entries map[rankingBaseKey]*rankingCacheEntry
+	// gen 無効化のたびに増える。集計中に無効化された場合は古いデータの可能性があるため保存しない
+	gen uint64
+}
+
+func newRankingCache() *rankingCache {
+	return &rankingCache{entries: make(map[rankingBaseKey]*rankingCacheEntry)}
+}
+
+// get 有効なキャッシュと、再計算が必要なユーザー、現在の世代を返す。
+// 再計算が必要なユーザーは取り出した時点で消すため、再計算に失敗した場合は invalidate し直すこと
+func (c *rankingCache) get(key rankingBaseKey, now time.Time) (*rankingBase, []uint, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, nil, c.gen
+	}
+	if now.Sub(entry.base.computedAt) > rankingCacheTTL {
+		delete(c.entries, key)
+		return nil, nil, c.gen
+	}
+	var dirty []uint
+	for id := range entry.dirty {
+		dirty = append(dirty, id)
+	}
+	entry.dirty = nil
+	return entry.base, dirty, c.gen
+}
+
+// put base を保存する。get の後（世代 gen から）に無効化されていた場合は保存せずに false を返す
+func (c *rankingCache) put(key rankingBaseKey, base *rankingBase, gen uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen != gen {
+		return false
+	}
+	entry, ok := c.entries[key]
+	if ok {
+		entry.base = base
+		return true
+	}
+
+	if len(c.entries) >= rankingCacheSize {
+		// 最も古い期間を捨てる
+		var oldest rankingBaseKey
+		var oldestAt time.Time
+		for k, e := range c.entries {
+			if oldestAt.IsZero() || e.base.computedAt.Before(oldestAt) {
+				oldest, oldestAt = k, e.base.computedAt
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = &rankingCacheEntry{base: base}
+	return true
+}
+
+func (c *rankingCache) invalidate(userIDs ...uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	if len(userIDs) == 0 {
+		c.entries = make(map[rankingBaseKey]*rankingCacheEntry)
+		return
+	}
+	for _, entry := range c.entries {
+		if entry.dirty == nil {
+			entry.dirty = make(map[uint]bool)
+		}
+		for _, id := range userIDs {
+			entry.dirty[id] = true
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeRankingRepo 日次集計をメモリ上で集計する AttendanceRepository（ランキングで使うメソッドのみ）
+type fakeRankingRepo struct {
+	repository.AttendanceRepository
+	dailies       []domain.DailyAttendance
+	rankingCalls  int
+	rankingUserID []uint
+}
+
+func (r *fakeRankingRepo) GetUserRanking(ctx context.Context, from, to time.Time, excludeHolidays bool, userIDs ...uint) ([]domain.UserRanking, error) {
+	r.rankingCalls++
+	r.rankingUserID = userIDs
+	byUser := make(map[uint]*domain.UserRanking)
+	var order []uint
+	for _, d := range r.filter(from, to, userIDs) {
+		u, ok := byUser[d.UserID]
+		if !ok {
+			u = &domain.UserRanking{UserID: d.UserID}
+			byUser[d.UserID] = u
+			order = append(order, d.UserID)
+		}
+		u.TotalDuration += d.TotalDurationMinutes
+		u.AttendanceDays++
+	}
+	rankings := make([]domain.UserRanking, 0, len(order))
+	for _, id := range order {
+		rankings = append(rankings, *byUser[id])
+	}
+	return rankings, nil
+}
+
+func (r *fakeRankingRepo) GetAttendanceDays(ctx context.Context, from, to time.Time, excludeHolidays bool, userIDs ...uint) ([]domain.DailyAttendance, error) {
+	return r.filter(from, to, userIDs), nil
+}
+
+func (r *fakeRankingRepo) GetRankedOpenSessions(ctx context.Context) ([]domain.CheckInLog, error) {
+	return nil, nil
+}
+
+func (r *fakeRankingRepo) filter(from, to time.Time, userIDs []uint) []domain.DailyAttendance {
+	var dailies []domain.DailyAttendance
+	for _, d := range r.dailies {
+		if d.AttendanceDate.Before(from) || !d.AttendanceDate.Before(to) {
+			continue
+		}
+		if len(userIDs) > 0 && !containsUserID(userIDs, d.UserID) {
+			continue
+		}
+		dailies = append(dailies, d)
+	}
+	return dailies
+}
+
+func containsUserID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeSettingsRepo 設定が1つも無い SettingsRepository
+type fakeSettingsRepo struct {
+	repository.SettingsRepository
+}
+
+func (fakeSettingsRepo) GetValue(ctx context.Context, key string, dest interface{}) error {
+	return gorm.ErrRecordNotFound
+}
+
+// newFakeRankingRepo users 人が from から days 日間毎日出席した日次集計
+func newFakeRankingRepo(users, days int, from time.Time) *fakeRankingRepo {
+	repo := &fakeRankingRepo{}
+	for d := 0; d < days; d++ {
+		for u := 1; u <= users; u++ {
+			repo.dailies = append(repo.dailies, domain.DailyAttendance{
+				UserID:               uint(u),
+				AttendanceDate:       from.AddDate(0, 0, d),
+				TotalDurationMinutes: 60 * u,
+			})
+		}
+	}
+	return repo
+}
+
+func TestRankingService_InvalidateRecomputesOnlyChangedUsers(t *testing.T) {
+	loc := time.UTC
+	repo := newFakeRankingRepo(3, 30, time.Date(2026, 4, 1, 0, 0, 0, 0, loc))
+	s := NewRankingService(repo, nil, fakeSettingsRepo{}, loc)
+	ctx := context.Background()
+	query := RankingQuery{Period: RankingPeriodCustom, Metric: RankingMetricMinutes, From: "2026-04-01", To: "2026-04-30"}
+
+	if _, err := s.GetRanking(ctx, query); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetRanking(ctx, query); err != nil {
+		t.Fatal(err)
+	}
+	if repo.rankingCalls != 1 {
+		t.Fatalf("キャッシュが使われていない: %d回集計した", repo.rankingCalls)
+	}
+
+	// ユーザー1の出席が増えた
+	repo.dailies = append(repo.dailies, domain.DailyAttendance{
+		UserID: 1, AttendanceDate: time.Date(2026, 4, 1, 0, 0, 0, 0, loc), TotalDurationMinutes: 10000,
+	})
+	s.Invalidate(1)
+
+	result, err := s.GetRanking(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.rankingCalls != 2 || len(repo.rankingUserID) != 1 || repo.rankingUserID[0] != 1 {
+		t.Fatalf("ユーザー1だけを集計し直すはず: calls=%d users=%v", repo.rankingCalls, repo.rankingUserID)
+	}
+	if len(result.Rankings) != 3 || result.Rankings[0].UserID != 1 || result.Rankings[0].Value != 30*60+10000 {
+		t.Errorf("got %+v", result.Rankings)
+	}
+}
+
+// BenchmarkGetRanking 30人が1年間出席した日次集計で、キャッシュの有無による差を測る
+func BenchmarkGetRanking(b *testing.B) {
+	loc := time.UTC
+	ctx := context.Background()
+	query := RankingQuery{Period: RankingPeriodCustom, Metric: RankingMetricMinutes, From: "2025-04-01", To: "2026-03-31"}
+
+	b.Run("uncached", func(b *testing.B) {
+		s := NewRankingService(newFakeRankingRepo(30, 365, time.Date(2025, 4, 1, 0, 0, 0, 0, loc)), nil, fakeSettingsRepo{}, loc)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s.Invalidate()
+			if _, err := s.GetRanking(ctx, query); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		s := NewRankingService(newFakeRankingRepo(30, 365, time.Date(2025, 4, 1, 0, 0, 0, 0, loc)), nil, fakeSettingsRepo{}, loc)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := s.GetRanking(ctx, query); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached_after_checkout", func(b *testing.B) {
+		// チェックアウトのたびに1人分だけ集計し直す
+		s := NewRankingService(newFakeRankingRepo(30, 365, time.Date(2025, 4, 1, 0, 0, 0, 0, loc)), nil, fakeSettingsRepo{}, loc)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			s.Invalidate(uint(i%30 + 1))
+			if _, err := s.GetRanking(ctx, query); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
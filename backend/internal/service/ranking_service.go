@@ -2,87 +2,554 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/kasa021/watabe-lab-app/internal/domain"
 	"github.com/kasa021/watabe-lab-app/internal/repository"
 )
 
+var ErrInvalidRankingQuery = errors.New("invalid ranking query")
+
+// maxCustomRankingDays custom の期間の最大日数（1年分）
+const maxCustomRankingDays = 366
+
+// ランキングの期間
+const (
+	RankingPeriodWeekly  = "weekly"
+	RankingPeriodMonthly = "monthly"
+	RankingPeriodTotal   = "total"
+	RankingPeriodCustom  = "custom" // From 〜 To の任意の期間
+	RankingPeriodTerm    = "term"   // academic_terms 設定の学期
+)
+
+// ランキングの指標
+const (
+	RankingMetricMinutes       = "minutes"        // 滞在時間（分）
+	RankingMetricDays          = "days"           // 出席日数
+	RankingMetricCurrentStreak = "current_streak" // 現在の連続出席日数
+	RankingMetricLongestStreak = "longest_streak" // 期間内に出席した連続出席の最長日数（期間の前から続く分も数える）
+	RankingMetricPoints        = "points"         // 獲得ポイント
+)
+
+// rankingMetrics ランキングの指標の一覧
+var rankingMetrics = []string{
+	RankingMetricMinutes,
+	RankingMetricDays,
+	RankingMetricCurrentStreak,
+	RankingMetricLongestStreak,
+	RankingMetricPoints,
+}
+
+func isRankingMetric(metric string) bool {
+	for _, m := range rankingMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// RankingQuery ランキングの取得条件
+type RankingQuery struct {
+	Period string
+	Metric string
+	// From, To custom の期間（"2006-01-02"、To の日を含む）
+	From string
+	To   string
+	// Term, AcademicYear term の学期名と年度（年度が0なら今年度）
+	Term         string
+	AcademicYear int
+	// ComparePrevious 直前の期間の順位と比べる
+	ComparePrevious bool
+}
+
+// RankingRange ランキングの期間（To の日を含む）
+type RankingRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RankingResult ランキングと集計した期間
+type RankingResult struct {
+	Period string `json:"period"`
+	Metric string `json:"metric"`
+	// Range 集計した期間（total の場合は無し）
+	Range *RankingRange `json:"range,omitempty"`
+	// Previous 比較した直前の期間
+	Previous *RankingRange        `json:"previous,omitempty"`
+	Rankings []domain.UserRanking `json:"rankings"`
+	// ComputedAt 集計に使ったキャッシュの作成時刻（在室中の滞在は取得時点で加えている）
+	ComputedAt time.Time `json:"-"`
+}
+
 type RankingService interface {
-	GetWeeklyRanking(ctx context.Context) ([]domain.UserRanking, error)
-	GetMonthlyRanking(ctx context.Context) ([]domain.UserRanking, error)
-	GetTotalRanking(ctx context.Context) ([]domain.UserRanking, error)
+	// GetRanking は期間内の指標の値が大きい順に、順位を付けたランキングを返す。
+	// 期間ごとの集計はキャッシュし、Invalidate されたユーザーの分だけ再計算する
+	GetRanking(ctx context.Context, query RankingQuery) (*RankingResult, error)
+	RankingInvalidator
 }
 
 type rankingService struct {
-	repo repository.AttendanceRepository
+	repo         repository.AttendanceRepository
+	pointRepo    repository.PointRepository
+	settingsRepo repository.SettingsRepository
+	loc          *time.Location
+	cache        *rankingCache
 }
 
-func NewRankingService(repo repository.AttendanceRepository) RankingService {
-	return &rankingService{repo: repo}
+func NewRankingService(repo repository.AttendanceRepository, pointRepo repository.PointRepository, settingsRepo repository.SettingsRepository, loc *time.Location) RankingService {
+	return &rankingService{repo: repo, pointRepo: pointRepo, settingsRepo: settingsRepo, loc: loc, cache: newRankingCache()}
 }
 
-func (s *rankingService) GetWeeklyRanking(ctx context.Context) ([]domain.UserRanking, error) {
+func (s *rankingService) Invalidate(userIDs ...uint) {
+	s.cache.invalidate(userIDs...)
+}
+
+func (s *rankingService) GetRanking(ctx context.Context, query RankingQuery) (*RankingResult, error) {
+	if !isRankingMetric(query.Metric) {
+		return nil, fmt.Errorf("metric %q: %w", query.Metric, ErrInvalidRankingQuery)
+	}
+
 	now := time.Now()
-	// 週の開始（月曜日）を取得
-	offset := int(now.Weekday())
-	if offset == 0 { // 日曜日の場合
-		offset = 7
+	from, to, prevFrom, prevTo, err := s.rankingRange(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	if query.ComparePrevious && prevFrom.IsZero() {
+		return nil, fmt.Errorf("type %q has no previous period: %w", query.Period, ErrInvalidRankingQuery)
 	}
-	startOfWeek := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -offset+1)
-	endOfWeek := startOfWeek.AddDate(0, 0, 7)
 
-	return s.repo.GetUserRanking(ctx, startOfWeek, endOfWeek)
-}
+	rules, err := loadHolidayRules(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *rankingService) GetMonthlyRanking(ctx context.Context) ([]domain.UserRanking, error) {
-	now := time.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-	endOfMonth := startOfMonth.AddDate(0, 1, 0)
+	rankings, computedAt, err := s.rank(ctx, query.Metric, from, to, now, rules)
+	if err != nil {
+		return nil, err
+	}
+	result := &RankingResult{Period: query.Period, Metric: query.Metric, Rankings: rankings, ComputedAt: computedAt}
+	if query.Period != RankingPeriodTotal {
+		result.Range = newRankingRange(from, to)
+	}
 
-	return s.repo.GetUserRanking(ctx, startOfMonth, endOfMonth)
+	if query.ComparePrevious {
+		previous, prevComputedAt, err := s.rank(ctx, query.Metric, prevFrom, prevTo, now, rules)
+		if err != nil {
+			return nil, err
+		}
+		if prevComputedAt.Before(result.ComputedAt) {
+			result.ComputedAt = prevComputedAt
+		}
+		applyRankDeltas(rankings, previous)
+		result.Previous = newRankingRange(prevFrom, prevTo)
+	}
+	return result, nil
 }
 
-func (s *rankingService) GetTotalRanking(ctx context.Context) ([]domain.UserRanking, error) {
-	// 全期間なので、十分に古い日付から未来まで
-	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
-	end := time.Now().AddDate(100, 0, 0)
+// rankingRange 期間 [from, to) と直前の期間 [prevFrom, prevTo)（直前の期間が無い場合はゼロ値）
+func (s *rankingService) rankingRange(ctx context.Context, query RankingQuery, now time.Time) (from, to, prevFrom, prevTo time.Time, err error) {
+	if query.Period != RankingPeriodCustom && (query.From != "" || query.To != "") {
+		return from, to, prevFrom, prevTo, fmt.Errorf("from/to require type %q: %w", RankingPeriodCustom, ErrInvalidRankingQuery)
+	}
+	if query.Period != RankingPeriodTerm && (query.Term != "" || query.AcademicYear != 0) {
+		return from, to, prevFrom, prevTo, fmt.Errorf("term/year require type %q: %w", RankingPeriodTerm, ErrInvalidRankingQuery)
+	}
 
-	return s.repo.GetUserRanking(ctx, start, end)
+	switch query.Period {
+	case RankingPeriodWeekly:
+		from, to = weekRange(now, s.loc)
+		prevFrom, prevTo = from.AddDate(0, 0, -7), from
+	case RankingPeriodMonthly:
+		from, to = monthRange(now, s.loc)
+		prevFrom, prevTo = from.AddDate(0, -1, 0), from
+	case RankingPeriodTotal:
+		// 全期間なので、十分に古い日付から未来まで（キャッシュのキーになるため固定の日付にする）
+		from, to = rankingHistoryStart(s.loc), rankingHistoryEnd(s.loc)
+	case RankingPeriodCustom:
+		if query.From == "" || query.To == "" {
+			return from, to, prevFrom, prevTo, fmt.Errorf("from and to are required: %w", ErrInvalidRankingQuery)
+		}
+		if from, err = time.ParseInLocation("2006-01-02", query.From, s.loc); err != nil {
+			return from, to, prevFrom, prevTo, fmt.Errorf("from %q: %w", query.From, ErrInvalidRankingQuery)
+		}
+		last, err := time.ParseInLocation("2006-01-02", query.To, s.loc)
+		if err != nil {
+			return from, to, prevFrom, prevTo, fmt.Errorf("to %q: %w", query.To, ErrInvalidRankingQuery)
+		}
+		if last.Before(from) {
+			return from, to, prevFrom, prevTo, fmt.Errorf("from %s is after to %s: %w", query.From, query.To, ErrInvalidRankingQuery)
+		}
+		to = last.AddDate(0, 0, 1)
+		if from.Before(rankingHistoryStart(s.loc)) || to.After(rankingHistoryEnd(s.loc)) {
+			return from, to, prevFrom, prevTo, fmt.Errorf("from %s to %s is out of range: %w", query.From, query.To, ErrInvalidRankingQuery)
+		}
+		// 直前の同じ日数の期間
+		days := int(to.Sub(from).Hours()/24 + 0.5)
+		if days > maxCustomRankingDays {
+			return from, to, prevFrom, prevTo, fmt.Errorf("range of %d days exceeds %d: %w", days, maxCustomRankingDays, ErrInvalidRankingQuery)
+		}
+		prevFrom, prevTo = from.AddDate(0, 0, -days), from
+	case RankingPeriodTerm:
+		terms, err := loadAcademicTerms(ctx, s.settingsRepo)
+		if err != nil {
+			return from, to, prevFrom, prevTo, err
+		}
+		year := query.AcademicYear
+		if year == 0 {
+			year = academicYearOf(now, s.loc)
+		}
+		if year < rankingHistoryStart(s.loc).Year() || year >= rankingHistoryEnd(s.loc).Year() {
+			return from, to, prevFrom, prevTo, fmt.Errorf("year %d: %w", year, ErrInvalidRankingQuery)
+		}
+		if _, ok := terms[query.Term]; !ok {
+			return from, to, prevFrom, prevTo, fmt.Errorf("term %q: %w", query.Term, ErrInvalidRankingQuery)
+		}
+		if from, to, err = academicTermRange(terms, query.Term, year, s.loc); err != nil {
+			return from, to, prevFrom, prevTo, err
+		}
+		prevTerm, prevYear, err := previousAcademicTerm(terms, query.Term, year, s.loc)
+		if err != nil {
+			return from, to, prevFrom, prevTo, err
+		}
+		if prevFrom, prevTo, err = academicTermRange(terms, prevTerm, prevYear, s.loc); err != nil {
+			return from, to, prevFrom, prevTo, err
+		}
+	default:
+		return from, to, prevFrom, prevTo, fmt.Errorf("type %q: %w", query.Period, ErrInvalidRankingQuery)
+	}
+	return from, to, prevFrom, prevTo, nil
 }
 
-// Handler integration
-type RankingHandler struct {
-	service RankingService
+// rank 期間 [from, to) の指標でランキングを作る。集計に使ったキャッシュの作成時刻も返す
+func (s *rankingService) rank(ctx context.Context, metric string, from, to, now time.Time, rules HolidayRules) ([]domain.UserRanking, time.Time, error) {
+	points := metric == RankingMetricPoints
+	base, err := s.base(ctx, points, from, to, rules.RankingExcludesHolidays, now)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	rankings := make([]domain.UserRanking, 0, len(base.rankings))
+	for _, r := range base.rankings {
+		rankings = append(rankings, r)
+	}
+	if points {
+		for i := range rankings {
+			rankings[i].Metric, rankings[i].Value = metric, rankings[i].TotalPoints
+		}
+		return rankUsers(rankings), base.computedAt, nil
+	}
+
+	holidays, err := loadHolidayCalendar(ctx, s.settingsRepo)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// 在室中のセッションは日次集計に入っていないため、now までの滞在を加える
+	sessions, err := s.repo.GetRankedOpenSessions(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var skipDay func(time.Time) bool
+	if rules.RankingExcludesHolidays {
+		skipDay = holidays.IsHoliday
+	}
+	open := openSessionDays(sessions, from, to, now, s.loc, skipDay)
+
+	computedAt := base.computedAt
+	streak := metric == RankingMetricCurrentStreak || metric == RankingMetricLongestStreak
+	baseDays := base.days
+	if streak {
+		// 連続出席は期間の前から続いている場合があるため、出席日は全期間の集計から取る
+		history, err := s.base(ctx, false, rankingHistoryStart(s.loc), to, rules.RankingExcludesHolidays, now)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		baseDays = history.days
+		if history.computedAt.Before(computedAt) {
+			computedAt = history.computedAt
+		}
+	}
+
+	// キャッシュを書き換えないよう、出席日はコピーに加える
+	days := make(map[uint][]time.Time, len(baseDays))
+	for userID, d := range baseDays {
+		days[userID] = d
+	}
+	rankings = addOpenSessions(rankings, sessions, open, days)
+
+	// 過去の期間では期間の最終日時点の連続出席日数
+	asOf := startOfDay(now, s.loc)
+	if !asOf.Before(to) {
+		asOf = to.AddDate(0, 0, -1)
+	}
+	setRankingValues(rankings, metric, days, from, asOf, holidays.isRestDay(rules))
+	return rankUsers(rankings), computedAt, nil
 }
 
-func NewRankingHandler(service RankingService) *RankingHandler {
-	return &RankingHandler{service: service}
+// setRankingValues rankings の Metric と Value を指標の値にする。
+// 連続出席の指標は days（日付順の出席日）から asOf の日時点で数え、最長は from 以降に出席した連続のみ対象にする
+func setRankingValues(rankings []domain.UserRanking, metric string, days map[uint][]time.Time, from, asOf time.Time, isRestDay func(time.Time) bool) {
+	for i := range rankings {
+		r := &rankings[i]
+		r.Metric = metric
+		switch metric {
+		case RankingMetricMinutes:
+			r.Value = r.TotalDuration
+		case RankingMetricDays:
+			r.Value = r.AttendanceDays
+		case RankingMetricPoints:
+			r.Value = r.TotalPoints
+		case RankingMetricCurrentStreak:
+			r.Value = currentStreak(daysUntil(days[r.UserID], asOf), asOf, isRestDay)
+		case RankingMetricLongestStreak:
+			r.Value = longestStreakSince(daysUntil(days[r.UserID], asOf), from, isRestDay)
+		}
+	}
 }
 
-func (h *RankingHandler) GetRankings(c *gin.Context) {
-	rankingType := c.DefaultQuery("type", "weekly")
+// base 期間の集計をキャッシュから取得する。キャッシュが無ければ全て、無効にされたユーザーがいればその分だけ集計する
+func (s *rankingService) base(ctx context.Context, points bool, from, to time.Time, excludeHolidays bool, now time.Time) (*rankingBase, error) {
+	key := rankingBaseKey{points: points, from: from.Unix(), to: to.Unix(), excludeHolidays: excludeHolidays}
+	cached, dirty, gen := s.cache.get(key, now)
+	if cached != nil && len(dirty) == 0 {
+		return cached, nil
+	}
 
 	var rankings []domain.UserRanking
+	var days map[uint][]time.Time
 	var err error
+	if points {
+		rankings, err = s.pointRepo.GetPointsRanking(ctx, from, to, dirty...)
+	} else if rankings, err = s.repo.GetUserRanking(ctx, from, to, excludeHolidays, dirty...); err == nil {
+		days, err = s.attendanceDays(ctx, from, to, excludeHolidays, dirty...)
+	}
+	if err != nil {
+		if len(dirty) > 0 {
+			s.cache.invalidate(dirty...)
+		}
+		return nil, err
+	}
 
-	switch rankingType {
-	case "weekly":
-		rankings, err = h.service.GetWeeklyRanking(c.Request.Context())
-	case "monthly":
-		rankings, err = h.service.GetMonthlyRanking(c.Request.Context())
-	case "total":
-		rankings, err = h.service.GetTotalRanking(c.Request.Context())
-	default:
-		// default to weekly
-		rankings, err = h.service.GetWeeklyRanking(c.Request.Context())
+	var base *rankingBase
+	if cached == nil {
+		base = (&rankingBase{computedAt: now}).update(nil, rankings, days)
+	} else {
+		base = cached.update(dirty, rankings, days)
+	}
+	if !s.cache.put(key, base, gen) && len(dirty) > 0 {
+		// 集計中に無効化された場合は、次の取得時にもう一度集計する
+		s.cache.invalidate(dirty...)
 	}
+	return base, nil
+}
+
+// newRankingRange [from, to) を最終日を含む日付の範囲にする
+func newRankingRange(from, to time.Time) *RankingRange {
+	return &RankingRange{From: from.Format("2006-01-02"), To: to.AddDate(0, 0, -1).Format("2006-01-02")}
+}
+
+// applyRankDeltas 直前の期間の順位と、そこからの順位の変化（上がった場合は正）を付ける
+func applyRankDeltas(rankings, previous []domain.UserRanking) {
+	prevRanks := make(map[uint]int, len(previous))
+	for _, r := range previous {
+		prevRanks[r.UserID] = r.Rank
+	}
+	for i := range rankings {
+		prevRank, ok := prevRanks[rankings[i].UserID]
+		if !ok {
+			continue
+		}
+		delta := prevRank - rankings[i].Rank
+		rankings[i].PreviousRank = &prevRank
+		rankings[i].RankDelta = &delta
+	}
+}
 
+// attendanceDays 期間 [from, to) のユーザーごとの出席日（日付順）
+func (s *rankingService) attendanceDays(ctx context.Context, from, to time.Time, excludeHolidays bool, userIDs ...uint) (map[uint][]time.Time, error) {
+	dailies, err := s.repo.GetAttendanceDays(ctx, from, to, excludeHolidays, userIDs...)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
-		return
+		return nil, err
+	}
+	return attendanceDaysByUser(dailies, s.loc), nil
+}
+
+// attendanceDaysByUser 日次集計をユーザーごとの出席日にする（dailies の順序のまま）
+func attendanceDaysByUser(dailies []domain.DailyAttendance, loc *time.Location) map[uint][]time.Time {
+	byUser := make(map[uint][]time.Time)
+	for _, d := range dailies {
+		// DATE 型は UTC の 0:00 として読み込まれるため、研究室のタイムゾーンの日付に直す
+		date := time.Date(d.AttendanceDate.Year(), d.AttendanceDate.Month(), d.AttendanceDate.Day(), 0, 0, 0, 0, loc)
+		byUser[d.UserID] = append(byUser[d.UserID], date)
+	}
+	return byUser
+}
+
+// openSessionDays 在室中のセッションの now までの滞在を日ごとに分け、期間 [from, to) の分をユーザーごとに返す。
+// skipDay が true を返す日（ランキングで数えない休日）は除く
+func openSessionDays(sessions []domain.CheckInLog, from, to, now time.Time, loc *time.Location, skipDay func(time.Time) bool) map[uint][]daySlice {
+	open := make(map[uint][]daySlice)
+	for _, l := range sessions {
+		if l.CheckOutAt != nil || !now.After(l.CheckInAt) {
+			continue
+		}
+		elapsed := int(now.Sub(l.CheckInAt).Minutes())
+		for _, slice := range splitByDay(l.CheckInAt, now, elapsed, loc) {
+			if slice.date.Before(from) || !slice.date.Before(to) || (skipDay != nil && skipDay(slice.date)) {
+				continue
+			}
+			open[l.UserID] = append(open[l.UserID], slice)
+		}
+	}
+	return open
+}
+
+// addOpenSessions 在室中の滞在をランキングに加える。日次集計に無い日は出席日として days にも加える。
+// 期間内に日次集計が無いユーザーは sessions のユーザー情報で追加する
+func addOpenSessions(rankings []domain.UserRanking, sessions []domain.CheckInLog, open map[uint][]daySlice, days map[uint][]time.Time) []domain.UserRanking {
+	if len(open) == 0 {
+		return rankings
+	}
+	index := make(map[uint]int, len(rankings))
+	for i, r := range rankings {
+		index[r.UserID] = i
+	}
+	for _, l := range sessions {
+		if _, ok := open[l.UserID]; !ok {
+			continue
+		}
+		if _, ok := index[l.UserID]; !ok {
+			index[l.UserID] = len(rankings)
+			rankings = append(rankings, domain.UserRanking{
+				UserID:            l.UserID,
+				DisplayName:       l.User.DisplayName,
+				Username:          l.User.Username,
+				ProfileVisibility: l.User.ProfileVisibility,
+			})
+		}
+	}
+
+	for userID, slices := range open {
+		i, ok := index[userID]
+		if !ok {
+			continue
+		}
+		r := &rankings[i]
+		// days の元の配列は書き換えない
+		userDays := append([]time.Time(nil), days[userID]...)
+		for _, slice := range slices {
+			r.TotalDuration += slice.minutes
+			if !containsDay(userDays, slice.date) {
+				userDays = append(userDays, slice.date)
+				r.AttendanceDays++
+			}
+		}
+		sort.Slice(userDays, func(a, b int) bool { return userDays[a].Before(userDays[b]) })
+		days[userID] = userDays
+	}
+	return rankings
+}
+
+func containsDay(days []time.Time, day time.Time) bool {
+	for _, d := range days {
+		if d.Equal(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// currentStreak asOf の日まで続いている連続出席日数（asOf の日にまだ来ていなくても前日までの連続は途切れていない）
+func currentStreak(days []time.Time, asOf time.Time, isRestDay func(time.Time) bool) int {
+	if len(days) == 0 {
+		return 0
+	}
+	last := days[len(days)-1]
+	if !last.Equal(asOf) && !isNextAttendanceDay(last, asOf, isRestDay) {
+		return 0
+	}
+	streak := 1
+	for i := len(days) - 1; i > 0 && isNextAttendanceDay(days[i-1], days[i], isRestDay); i-- {
+		streak++
+	}
+	return streak
+}
+
+// longestStreakSince from 以降に出席した日を含む連続出席のうち最長の日数（from より前から続く分も数える）
+func longestStreakSince(days []time.Time, from time.Time, isRestDay func(time.Time) bool) int {
+	longest, current := 0, 0
+	for i, day := range days {
+		if i > 0 && isNextAttendanceDay(days[i-1], day, isRestDay) {
+			current++
+		} else {
+			current = 1
+		}
+		if !day.Before(from) && current > longest {
+			longest = current
+		}
+	}
+	return longest
+}
+
+// daysUntil 日付順の days のうち asOf の日までの分
+func daysUntil(days []time.Time, asOf time.Time) []time.Time {
+	n := sort.Search(len(days), func(i int) bool { return days[i].After(asOf) })
+	return days[:n]
+}
+
+// rankingHistoryStart 全期間の集計の開始日（これより前の出席は無いものとする）
+func rankingHistoryStart(loc *time.Location) time.Time {
+	return time.Date(2000, 1, 1, 0, 0, 0, 0, loc)
+}
+
+// rankingHistoryEnd 全期間の集計の終了日。custom と term の期間もこの範囲に収める
+func rankingHistoryEnd(loc *time.Location) time.Time {
+	return time.Date(2100, 1, 1, 0, 0, 0, 0, loc)
+}
+
+// rankUsers 値が0のユーザーを除き、値の大きい順に並べて順位を付ける。
+// 同じ値は同順位とし、次の順位は同順位の人数分飛ばす（1, 1, 3, ...）
+func rankUsers(rankings []domain.UserRanking) []domain.UserRanking {
+	ranked := make([]domain.UserRanking, 0, len(rankings))
+	for _, r := range rankings {
+		if r.Value > 0 {
+			ranked = append(ranked, r)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Value != ranked[j].Value {
+			return ranked[i].Value > ranked[j].Value
+		}
+		return ranked[i].UserID < ranked[j].UserID
+	})
+	for i := range ranked {
+		if i > 0 && ranked[i].Value == ranked[i-1].Value {
+			ranked[i].Rank = ranked[i-1].Rank
+		} else {
+			ranked[i].Rank = i + 1
+		}
+	}
+	return ranked
+}
+
+// weekRange now を含む週（月曜 0:00 〜 翌週月曜 0:00、loc 基準）
+func weekRange(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	now = now.In(loc)
+	// 週の開始（月曜日）を取得
+	offset := int(now.Weekday())
+	if offset == 0 { // 日曜日の場合
+		offset = 7
 	}
+	start := time.Date(now.Year(), now.Month(), now.Day()-offset+1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 0, 7)
+}
 
-	c.JSON(200, gin.H{"rankings": rankings})
+// monthRange now を含む月（1日 0:00 〜 翌月1日 0:00、loc 基準）
+func monthRange(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	now = now.In(loc)
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	return start, start.AddDate(0, 1, 0)
 }
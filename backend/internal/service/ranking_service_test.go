@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+func TestRankUsers_Ties(t *testing.T) {
+	ranked := rankUsers([]domain.UserRanking{
+		{UserID: 1, Value: 120},
+		{UserID: 2, Value: 300},
+		{UserID: 3, Value: 120},
+		{UserID: 4, Value: 0},
+		{UserID: 5, Value: 60},
+	})
+
+	want := []struct {
+		userID uint
+		rank   int
+	}{{2, 1}, {1, 2}, {3, 2}, {5, 4}}
+	if len(ranked) != len(want) {
+		t.Fatalf("got %+v", ranked)
+	}
+	for i, w := range want {
+		if ranked[i].UserID != w.userID || ranked[i].Rank != w.rank {
+			t.Errorf("%d番目: got user %d rank %d, want user %d rank %d", i+1, ranked[i].UserID, ranked[i].Rank, w.userID, w.rank)
+		}
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 0, 0, 0, 0, time.UTC) }
+	weekends := holidayCalendar{}.isRestDay(HolidayRules{StreakSkipsWeekends: true})
+	noRest := holidayCalendar{}.isRestDay(HolidayRules{})
+
+	tests := []struct {
+		name      string
+		days      []time.Time
+		asOf      time.Time
+		isRestDay func(time.Time) bool
+		want      int
+	}{
+		{"今日まで連続", []time.Time{day(11), day(12), day(13)}, day(13), noRest, 3},
+		{"今日はまだ来ていない", []time.Time{day(11), day(12)}, day(13), noRest, 2},
+		{"途切れている", []time.Time{day(11), day(12)}, day(14), noRest, 0},
+		// 2026-05-08(金) → 05-11(月)
+		{"週末をはさむ", []time.Time{day(7), day(8), day(11)}, day(11), weekends, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := currentStreak(tt.days, tt.asOf, tt.isRestDay); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankingService_StreakCrossesPeriodStart(t *testing.T) {
+	loc := time.UTC
+	repo := &fakeRankingRepo{}
+	attend := func(userID uint, from, to time.Time) {
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			repo.dailies = append(repo.dailies, domain.DailyAttendance{UserID: userID, AttendanceDate: d, TotalDurationMinutes: 60})
+		}
+	}
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, loc) }
+	attend(1, day(4, 27), day(5, 6)) // 期間の前から続き、期間の途中で途切れる（10日）
+	attend(2, day(5, 4), day(5, 10)) // 期間内のみ（7日）
+	attend(2, day(5, 12), day(5, 31))
+	attend(3, day(4, 20), day(5, 10)) // 期間の前から最終日まで続く（21日）
+	attend(4, day(4, 1), day(5, 3))   // 期間内に出席していない
+	s := NewRankingService(repo, nil, fakeSettingsRepo{}, loc)
+
+	tests := []struct {
+		metric string
+		want   map[uint]int
+	}{
+		// 期間の最終日（5/10）時点。期間の後の出席は数えない
+		{RankingMetricCurrentStreak, map[uint]int{2: 7, 3: 21}},
+		{RankingMetricLongestStreak, map[uint]int{1: 10, 2: 7, 3: 21}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			result, err := s.GetRanking(context.Background(), RankingQuery{Period: RankingPeriodCustom, Metric: tt.metric, From: "2026-05-04", To: "2026-05-10"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[uint]int, len(result.Rankings))
+			for _, r := range result.Rankings {
+				got[r.UserID] = r.Value
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("rankings = %+v, want %v", result.Rankings, tt.want)
+			}
+			for userID, want := range tt.want {
+				if got[userID] != want {
+					t.Errorf("user %d = %d, want %d", userID, got[userID], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRankingService_CustomRange(t *testing.T) {
+	s := &rankingService{settingsRepo: fakeSettingsRepo{}, loc: time.UTC}
+	now := time.Date(2026, 5, 20, 9, 0, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	from, to, prevFrom, prevTo, err := s.rankingRange(context.Background(), RankingQuery{Period: RankingPeriodCustom, From: "2025-04-01", To: "2026-03-31"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !from.Equal(day(2025, 4, 1)) || !to.Equal(day(2026, 4, 1)) || !prevFrom.Equal(day(2024, 4, 1)) || !prevTo.Equal(from) {
+		t.Errorf("range = %v〜%v, previous = %v〜%v", from, to, prevFrom, prevTo)
+	}
+
+	tests := []struct {
+		name  string
+		query RankingQuery
+	}{
+		{"366日を超える", RankingQuery{Period: RankingPeriodCustom, From: "2025-04-01", To: "2026-04-02"}},
+		{"極端に長い", RankingQuery{Period: RankingPeriodCustom, From: "0001-01-01", To: "9999-12-31"}},
+		{"古すぎる", RankingQuery{Period: RankingPeriodCustom, From: "1999-12-31", To: "2000-01-06"}},
+		{"先すぎる", RankingQuery{Period: RankingPeriodCustom, From: "2099-12-31", To: "2100-01-01"}},
+		{"to が from より前", RankingQuery{Period: RankingPeriodCustom, From: "2026-05-02", To: "2026-05-01"}},
+		{"年度が範囲外", RankingQuery{Period: RankingPeriodTerm, Term: "spring", AcademicYear: 99999}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, _, err := s.rankingRange(context.Background(), tt.query, now); !errors.Is(err, ErrInvalidRankingQuery) {
+				t.Errorf("err = %v, want %v", err, ErrInvalidRankingQuery)
+			}
+		})
+	}
+}
+
+func TestAcademicTermRange(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, loc) }
+
+	tests := []struct {
+		term      string
+		year      int
+		wantStart time.Time
+		wantEnd   time.Time
+		prevTerm  string
+		prevYear  int
+	}{
+		{"spring", 2025, date(2025, 4, 1), date(2025, 10, 1), "fall", 2024},
+		// 後期は年をまたぎ、3月31日まで
+		{"fall", 2025, date(2025, 10, 1), date(2026, 4, 1), "spring", 2025},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			start, end, err := academicTermRange(defaultAcademicTerms, tt.term, tt.year, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("got [%s, %s), want [%s, %s)", start, end, tt.wantStart, tt.wantEnd)
+			}
+			prevTerm, prevYear, err := previousAcademicTerm(defaultAcademicTerms, tt.term, tt.year, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if prevTerm != tt.prevTerm || prevYear != tt.prevYear {
+				t.Errorf("previous: got %s %d, want %s %d", prevTerm, prevYear, tt.prevTerm, tt.prevYear)
+			}
+		})
+	}
+}
+
+func TestApplyRankDeltas(t *testing.T) {
+	rankings := []domain.UserRanking{{UserID: 1, Rank: 1}, {UserID: 2, Rank: 2}, {UserID: 3, Rank: 3}}
+	previous := []domain.UserRanking{{UserID: 2, Rank: 1}, {UserID: 1, Rank: 3}}
+
+	applyRankDeltas(rankings, previous)
+
+	if r := rankings[0]; r.PreviousRank == nil || *r.PreviousRank != 3 || *r.RankDelta != 2 {
+		t.Errorf("user 1: got %+v", r)
+	}
+	if r := rankings[1]; r.PreviousRank == nil || *r.PreviousRank != 1 || *r.RankDelta != -1 {
+		t.Errorf("user 2: got %+v", r)
+	}
+	if r := rankings[2]; r.PreviousRank != nil || r.RankDelta != nil {
+		t.Errorf("user 3 は前の期間に順位が無い: got %+v", r)
+	}
+}
+
+func TestAddOpenSessions(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	from := time.Date(2026, 5, 11, 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, 7)
+	now := time.Date(2026, 5, 12, 1, 0, 0, 0, loc)
+
+	rankings := []domain.UserRanking{{UserID: 1, TotalDuration: 120, AttendanceDays: 1}}
+	days := map[uint][]time.Time{1: {from}}
+	sessions := []domain.CheckInLog{
+		// 前日から在室中: 5/11 の 60分 + 5/12 の 60分
+		{UserID: 1, CheckInAt: time.Date(2026, 5, 11, 23, 0, 0, 0, loc)},
+		// 期間より前から在室中のユーザー（期間内の分だけ数える）
+		{UserID: 2, CheckInAt: time.Date(2026, 5, 10, 23, 30, 0, 0, loc), User: domain.User{DisplayName: "在室中"}},
+	}
+
+	open := openSessionDays(sessions, from, to, now, loc, nil)
+	rankings = addOpenSessions(rankings, sessions, open, days)
+
+	if len(rankings) != 2 {
+		t.Fatalf("got %+v", rankings)
+	}
+	if r := rankings[0]; r.TotalDuration != 240 || r.AttendanceDays != 2 {
+		t.Errorf("user 1: got %d分 %d日, want 240分 2日", r.TotalDuration, r.AttendanceDays)
+	}
+	if r := rankings[1]; r.UserID != 2 || r.DisplayName != "在室中" || r.TotalDuration != 25*60 || r.AttendanceDays != 2 {
+		t.Errorf("user 2: got %+v", r)
+	}
+	if len(days[1]) != 2 || !days[1][1].Equal(from.AddDate(0, 0, 1)) {
+		t.Errorf("user 1 の出席日: got %v", days[1])
+	}
+}
+
+func TestDiffRankings(t *testing.T) {
+	prev := map[uint]domain.UserRanking{
+		1: {UserID: 1, Rank: 1, Value: 300},
+		2: {UserID: 2, Rank: 2, Value: 200},
+		3: {UserID: 3, Rank: 3, Value: 100},
+		4: {UserID: 4, Rank: 4, Value: 50},
+	}
+	current := []domain.UserRanking{
+		{UserID: 2, Rank: 1, Value: 400},
+		{UserID: 1, Rank: 2, Value: 300},
+		{UserID: 3, Rank: 3, Value: 100},
+		{UserID: 5, Rank: 4, Value: 60},
+	}
+
+	changed, removed := diffRankings(prev, current)
+
+	if len(changed) != 3 {
+		t.Fatalf("変わった行: got %+v", changed)
+	}
+	if r := changed[0]; r.UserID != 2 || *r.PreviousRank != 2 || *r.RankDelta != 1 {
+		t.Errorf("user 2: got %+v", r)
+	}
+	if r := changed[1]; r.UserID != 1 || *r.RankDelta != -1 {
+		t.Errorf("user 1: got %+v", r)
+	}
+	if r := changed[2]; r.UserID != 5 || r.PreviousRank != nil {
+		t.Errorf("user 5 は新しく順位が付いた: got %+v", r)
+	}
+	if len(removed) != 1 || removed[0] != 4 {
+		t.Errorf("外れたユーザー: got %v", removed)
+	}
+}
+
+func TestMaskPrivateRankings(t *testing.T) {
+	rankings := []domain.UserRanking{
+		{UserID: 1, DisplayName: "公開", Username: "public", ProfileVisibility: domain.VisibilityPublic, Rank: 1},
+		{UserID: 2, DisplayName: "メンバー", Username: "members", ProfileVisibility: domain.VisibilityMembers, Rank: 2},
+		{UserID: 3, DisplayName: "非公開", Username: "private", ProfileVisibility: domain.VisibilityPrivate, Rank: 3, Value: 120},
+		{UserID: 4, DisplayName: "本人", Username: "me", ProfileVisibility: domain.VisibilityPrivate, Rank: 4},
+	}
+	MaskPrivateRankings(&domain.User{ID: 4, Role: "student", IsActive: true}, rankings)
+
+	for _, r := range rankings[:2] {
+		if r.IsPrivate || r.UserID == 0 {
+			t.Errorf("公開されている行が伏せられた: %+v", r)
+		}
+	}
+	if r := rankings[2]; !r.IsPrivate || r.UserID != 0 || r.Username != "" || r.DisplayName == "非公開" || r.Rank != 3 || r.Value != 120 {
+		t.Errorf("非公開の行: got %+v", r)
+	}
+	if r := rankings[3]; r.IsPrivate || r.UserID != 4 {
+		t.Errorf("本人の行は伏せない: got %+v", r)
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+var ErrRankingSnapshotNotFound = errors.New("ranking snapshot not found")
+
+// rankingSnapshotLimit 一覧・推移で返す期間の数
+const rankingSnapshotLimit = 60
+
+// RankingHistoryEntry ユーザーの順位の推移の1期間分
+type RankingHistoryEntry struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Rank  int    `json:"rank"`
+	Value int    `json:"value"`
+}
+
+// RankingSnapshotService 締めた週・月のランキングの保存と参照
+type RankingSnapshotService interface {
+	// SnapshotClosedPeriods は最後に保存した期間の次から now の直前に締まった期間までの週と月のランキングを
+	// 全ての指標で保存する（停止していた間の期間も遡って保存する）。保存した期間の数を返す。
+	// ランキングは日次集計のみから作り、公開設定に関係なく全てのユーザーを保存する（参照時に絞り込む）
+	SnapshotClosedPeriods(ctx context.Context, now time.Time) (int, error)
+	// ListPeriods は保存済みの期間を新しい順に返す
+	ListPeriods(ctx context.Context, period string) ([]RankingRange, error)
+	// GetSnapshot は from の日に始まる期間の保存済みのランキングを返す。
+	// 無効化されたユーザーと現在ランキングに参加していないユーザーを除き、順位を付け直す
+	GetSnapshot(ctx context.Context, period, metric, from string) (*RankingResult, error)
+	// GetUserHistory はユーザーの順位の推移を新しい順に返す（順位の無い期間は含まない）。
+	// 順位は GetSnapshot と同じく、現在ランキングに表示するユーザーの中で数え直す
+	GetUserHistory(ctx context.Context, userID uint, period, metric string) ([]RankingHistoryEntry, error)
+}
+
+type rankingSnapshotService struct {
+	repo           repository.RankingSnapshotRepository
+	attendanceRepo repository.AttendanceRepository
+	settingsRepo   repository.SettingsRepository
+	loc            *time.Location
+}
+
+func NewRankingSnapshotService(repo repository.RankingSnapshotRepository, attendanceRepo repository.AttendanceRepository, settingsRepo repository.SettingsRepository, loc *time.Location) RankingSnapshotService {
+	return &rankingSnapshotService{repo: repo, attendanceRepo: attendanceRepo, settingsRepo: settingsRepo, loc: loc}
+}
+
+func (s *rankingSnapshotService) SnapshotClosedPeriods(ctx context.Context, now time.Time) (int, error) {
+	saved := 0
+	for _, period := range []string{RankingPeriodWeekly, RankingPeriodMonthly} {
+		pending, err := s.pendingPeriods(ctx, period, now)
+		if err != nil {
+			return saved, err
+		}
+		for _, p := range pending {
+			if err := s.snapshot(ctx, period, p[0], p[1]); err != nil {
+				return saved, fmt.Errorf("%s %s: %w", period, p[0].Format("2006-01-02"), err)
+			}
+			saved++
+		}
+	}
+	return saved, nil
+}
+
+// pendingPeriods まだ保存していない締めた期間 [from, to) を古い順に返す。
+// 最後に保存した期間の次から now の直前に締まった期間までを、rankingSnapshotLimit 期間まで遡る。
+// 1つも保存していない場合は now の直前に締まった期間のみ
+func (s *rankingSnapshotService) pendingPeriods(ctx context.Context, period string, now time.Time) ([][2]time.Time, error) {
+	latest, err := s.repo.GetLatestPeriod(ctx, period)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var to time.Time
+	if period == RankingPeriodWeekly {
+		to, _ = weekRange(now, s.loc)
+	} else {
+		to, _ = monthRange(now, s.loc)
+	}
+
+	var pending [][2]time.Time
+	for len(pending) < rankingSnapshotLimit {
+		var from time.Time
+		if period == RankingPeriodWeekly {
+			from = to.AddDate(0, 0, -7)
+		} else {
+			from = to.AddDate(0, -1, 0)
+		}
+		if latest != nil && from.Format("2006-01-02") <= latest.PeriodStart.Format("2006-01-02") {
+			break
+		}
+		pending = append([][2]time.Time{{from, to}}, pending...)
+		if latest == nil {
+			break
+		}
+		to = from
+	}
+	return pending, nil
+}
+
+// snapshot 期間 [from, to) のランキングを全ての指標で保存する。
+// 在室中のセッションは含めず、連続出席は期間の最終日時点で数える
+func (s *rankingSnapshotService) snapshot(ctx context.Context, period string, from, to time.Time) error {
+	rules, err := loadHolidayRules(ctx, s.settingsRepo)
+	if err != nil {
+		return err
+	}
+	holidays, err := loadHolidayCalendar(ctx, s.settingsRepo)
+	if err != nil {
+		return err
+	}
+	totals, err := s.repo.GetPeriodTotals(ctx, from, to, rules.RankingExcludesHolidays)
+	if err != nil {
+		return err
+	}
+	// 連続出席は期間の前から続いている場合があるため、出席日は全期間から読み込む
+	dailies, err := s.attendanceRepo.GetAttendanceDays(ctx, rankingHistoryStart(s.loc), to, rules.RankingExcludesHolidays)
+	if err != nil {
+		return err
+	}
+	days := attendanceDaysByUser(dailies, s.loc)
+
+	last := to.AddDate(0, 0, -1)
+	isRestDay := holidays.isRestDay(rules)
+	var snapshots []domain.RankingSnapshot
+	for _, metric := range rankingMetrics {
+		rankings := append([]domain.UserRanking(nil), totals...)
+		setRankingValues(rankings, metric, days, from, last, isRestDay)
+		for _, r := range rankUsers(rankings) {
+			snapshots = append(snapshots, domain.RankingSnapshot{
+				Period:         period,
+				Metric:         metric,
+				PeriodStart:    from,
+				PeriodEnd:      last,
+				UserID:         r.UserID,
+				Rank:           r.Rank,
+				Value:          r.Value,
+				TotalDuration:  r.TotalDuration,
+				AttendanceDays: r.AttendanceDays,
+				TotalPoints:    r.TotalPoints,
+			})
+		}
+	}
+	return s.repo.CreateSnapshots(ctx, &domain.RankingSnapshotPeriod{Period: period, PeriodStart: from, PeriodEnd: last}, snapshots)
+}
+
+func (s *rankingSnapshotService) ListPeriods(ctx context.Context, period string) ([]RankingRange, error) {
+	if err := validateSnapshotQuery(period, RankingMetricMinutes); err != nil {
+		return nil, err
+	}
+	periods, err := s.repo.GetPeriods(ctx, period, rankingSnapshotLimit)
+	if err != nil {
+		return nil, err
+	}
+	ranges := make([]RankingRange, 0, len(periods))
+	for _, p := range periods {
+		ranges = append(ranges, RankingRange{From: p.PeriodStart.Format("2006-01-02"), To: p.PeriodEnd.Format("2006-01-02")})
+	}
+	return ranges, nil
+}
+
+func (s *rankingSnapshotService) GetSnapshot(ctx context.Context, period, metric, from string) (*RankingResult, error) {
+	if err := validateSnapshotQuery(period, metric); err != nil {
+		return nil, err
+	}
+	start, err := time.ParseInLocation("2006-01-02", from, s.loc)
+	if err != nil {
+		return nil, fmt.Errorf("from %q: %w", from, ErrInvalidRankingQuery)
+	}
+
+	exists, err := s.repo.HasSnapshot(ctx, period, start)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrRankingSnapshotNotFound
+	}
+	rankings, err := s.repo.GetSnapshot(ctx, period, metric, start)
+	if err != nil {
+		return nil, err
+	}
+	// 保存後にランキングから外れたユーザーの分の順位を詰める
+	rankings = rankUsers(rankings)
+
+	var end time.Time
+	if period == RankingPeriodWeekly {
+		end = start.AddDate(0, 0, 7)
+	} else {
+		end = start.AddDate(0, 1, 0)
+	}
+	return &RankingResult{Period: period, Metric: metric, Range: newRankingRange(start, end), Rankings: rankings}, nil
+}
+
+func (s *rankingSnapshotService) GetUserHistory(ctx context.Context, userID uint, period, metric string) ([]RankingHistoryEntry, error) {
+	if err := validateSnapshotQuery(period, metric); err != nil {
+		return nil, err
+	}
+	snapshots, err := s.repo.GetUserHistory(ctx, userID, period, metric, rankingSnapshotLimit)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]RankingHistoryEntry, 0, len(snapshots))
+	for _, sn := range snapshots {
+		history = append(history, RankingHistoryEntry{
+			From:  sn.PeriodStart.Format("2006-01-02"),
+			To:    sn.PeriodEnd.Format("2006-01-02"),
+			Rank:  sn.Rank,
+			Value: sn.Value,
+		})
+	}
+	return history, nil
+}
+
+// validateSnapshotQuery スナップショットを保存している期間の種類と指標か
+func validateSnapshotQuery(period, metric string) error {
+	if period != RankingPeriodWeekly && period != RankingPeriodMonthly {
+		return fmt.Errorf("type %q: %w", period, ErrInvalidRankingQuery)
+	}
+	if !isRankingMetric(metric) {
+		return fmt.Errorf("metric %q: %w", metric, ErrInvalidRankingQuery)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeSnapshotRepo 保存した期間とスナップショットをメモリ上に持つ RankingSnapshotRepository
+type fakeSnapshotRepo struct {
+	repository.RankingSnapshotRepository
+	periods   []domain.RankingSnapshotPeriod
+	snapshots []domain.RankingSnapshot
+	// rankings GetSnapshot が返す行
+	rankings []domain.UserRanking
+	// totals GetPeriodTotals が返す行（期間の初日ごと）
+	totals map[string][]domain.UserRanking
+}
+
+func (r *fakeSnapshotRepo) HasSnapshot(ctx context.Context, period string, periodStart time.Time) (bool, error) {
+	for _, p := range r.periods {
+		if p.Period == period && p.PeriodStart.Equal(periodStart) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeSnapshotRepo) GetLatestPeriod(ctx context.Context, period string) (*domain.RankingSnapshotPeriod, error) {
+	var latest *domain.RankingSnapshotPeriod
+	for i, p := range r.periods {
+		if p.Period == period && (latest == nil || p.PeriodStart.After(latest.PeriodStart)) {
+			latest = &r.periods[i]
+		}
+	}
+	if latest == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return latest, nil
+}
+
+func (r *fakeSnapshotRepo) CreateSnapshots(ctx context.Context, period *domain.RankingSnapshotPeriod, snapshots []domain.RankingSnapshot) error {
+	r.periods = append(r.periods, *period)
+	r.snapshots = append(r.snapshots, snapshots...)
+	return nil
+}
+
+func (r *fakeSnapshotRepo) GetSnapshot(ctx context.Context, period, metric string, periodStart time.Time) ([]domain.UserRanking, error) {
+	return r.rankings, nil
+}
+
+func (r *fakeSnapshotRepo) GetPeriodTotals(ctx context.Context, from, to time.Time, excludeHolidays bool) ([]domain.UserRanking, error) {
+	return r.totals[from.Format("2006-01-02")], nil
+}
+
+// newTestSnapshotService 出席日を attendanceRepo から読み込む RankingSnapshotService
+func newTestSnapshotService(repo *fakeSnapshotRepo, attendanceRepo *fakeRankingRepo) RankingSnapshotService {
+	if attendanceRepo == nil {
+		attendanceRepo = &fakeRankingRepo{}
+	}
+	return NewRankingSnapshotService(repo, attendanceRepo, fakeSettingsRepo{}, time.UTC)
+}
+
+func TestSnapshotClosedPeriods(t *testing.T) {
+	loc := time.UTC
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, loc) }
+	// 2026-05-20（水）。直前に締まった週は 5/11〜5/17、月は 4月
+	now := time.Date(2026, 5, 20, 9, 0, 0, 0, loc)
+
+	t.Run("保存済みの期間が無い場合は直前の期間のみ", func(t *testing.T) {
+		repo := &fakeSnapshotRepo{}
+		s := newTestSnapshotService(repo, nil)
+
+		saved, err := s.SnapshotClosedPeriods(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved != 2 {
+			t.Fatalf("saved = %d, want 2", saved)
+		}
+		// 順位の無い期間も保存済みとして記録する
+		if !repo.periods[0].PeriodStart.Equal(day(5, 11)) || !repo.periods[1].PeriodStart.Equal(day(4, 1)) || len(repo.snapshots) != 0 {
+			t.Errorf("periods = %+v, snapshots = %d", repo.periods, len(repo.snapshots))
+		}
+
+		// 2回目は何もしない
+		if saved, err := s.SnapshotClosedPeriods(context.Background(), now); err != nil || saved != 0 {
+			t.Errorf("saved = %d, err = %v, want 0", saved, err)
+		}
+	})
+
+	t.Run("最後に保存した期間の次から遡って保存する", func(t *testing.T) {
+		repo := &fakeSnapshotRepo{periods: []domain.RankingSnapshotPeriod{
+			{Period: RankingPeriodWeekly, PeriodStart: day(4, 27), PeriodEnd: day(5, 3)},
+			{Period: RankingPeriodMonthly, PeriodStart: day(4, 1), PeriodEnd: day(4, 30)},
+		}, totals: map[string][]domain.UserRanking{
+			"2026-05-04": {{UserID: 1, TotalDuration: 60, AttendanceDays: 1, TotalPoints: 1}},
+		}}
+		s := newTestSnapshotService(repo, &fakeRankingRepo{dailies: []domain.DailyAttendance{{UserID: 1, AttendanceDate: day(5, 10)}}})
+
+		saved, err := s.SnapshotClosedPeriods(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved != 2 {
+			t.Fatalf("saved = %d, want 2", saved)
+		}
+		added := repo.periods[2:]
+		if !added[0].PeriodStart.Equal(day(5, 4)) || !added[1].PeriodStart.Equal(day(5, 11)) || !added[1].PeriodEnd.Equal(day(5, 17)) {
+			t.Errorf("added periods = %+v", added)
+		}
+		if len(repo.snapshots) != len(rankingMetrics) || !repo.snapshots[0].PeriodStart.Equal(day(5, 4)) {
+			t.Errorf("snapshots = %+v", repo.snapshots)
+		}
+	})
+}
+
+func TestGetSnapshot_Reranks(t *testing.T) {
+	// 1位のユーザーが保存後にランキングから外れた
+	repo := &fakeSnapshotRepo{
+		periods: []domain.RankingSnapshotPeriod{{Period: RankingPeriodWeekly, PeriodStart: time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC)}},
+		rankings: []domain.UserRanking{
+			{UserID: 2, Rank: 2, Value: 200},
+			{UserID: 3, Rank: 3, Value: 100},
+			{UserID: 4, Rank: 3, Value: 100},
+		},
+	}
+	s := newTestSnapshotService(repo, nil)
+
+	result, err := s.GetSnapshot(context.Background(), RankingPeriodWeekly, RankingMetricMinutes, "2026-05-11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ranks []int
+	for _, r := range result.Rankings {
+		ranks = append(ranks, r.Rank)
+	}
+	if len(ranks) != 3 || ranks[0] != 1 || ranks[1] != 2 || ranks[2] != 2 {
+		t.Errorf("ranks = %v, want [1 2 2]", ranks)
+	}
+}
+
+func TestPendingPeriods(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	// 2026-05-20（水）。直前に締まった週は 5/11〜5/17、月は 4月
+	now := time.Date(2026, 5, 20, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		period    string
+		latest    time.Time // 最後に保存した期間の初日（ゼロ値なら保存していない）
+		wantCount int
+		wantFirst time.Time
+	}{
+		{"保存していない週", RankingPeriodWeekly, time.Time{}, 1, day(2026, 5, 11)},
+		{"保存していない月", RankingPeriodMonthly, time.Time{}, 1, day(2026, 4, 1)},
+		{"直前の週まで保存済み", RankingPeriodWeekly, day(2026, 5, 11), 0, time.Time{}},
+		{"3週分遡る", RankingPeriodWeekly, day(2026, 4, 20), 3, day(2026, 4, 27)},
+		{"2か月分遡る", RankingPeriodMonthly, day(2026, 2, 1), 2, day(2026, 3, 1)},
+		{"週は上限まで", RankingPeriodWeekly, day(2020, 1, 6), rankingSnapshotLimit, day(2026, 5, 11).AddDate(0, 0, -7*(rankingSnapshotLimit-1))},
+		{"月は上限まで", RankingPeriodMonthly, day(2010, 1, 1), rankingSnapshotLimit, day(2026, 4, 1).AddDate(0, -(rankingSnapshotLimit - 1), 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSnapshotRepo{}
+			if !tt.latest.IsZero() {
+				repo.periods = []domain.RankingSnapshotPeriod{{Period: tt.period, PeriodStart: tt.latest}}
+			}
+			s := newTestSnapshotService(repo, nil).(*rankingSnapshotService)
+
+			pending, err := s.pendingPeriods(context.Background(), tt.period, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pending) != tt.wantCount {
+				t.Fatalf("pending = %d periods, want %d", len(pending), tt.wantCount)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			if !pending[0][0].Equal(tt.wantFirst) {
+				t.Errorf("first period starts %v, want %v", pending[0][0], tt.wantFirst)
+			}
+			// 古い順に隙間なく続き、最後は直前に締まった期間
+			for i := 1; i < len(pending); i++ {
+				if !pending[i][0].Equal(pending[i-1][1]) {
+					t.Fatalf("period %d starts %v, previous ends %v", i, pending[i][0], pending[i-1][1])
+				}
+			}
+			var wantEnd time.Time
+			if tt.period == RankingPeriodWeekly {
+				wantEnd = day(2026, 5, 18)
+			} else {
+				wantEnd = day(2026, 5, 1)
+			}
+			if last := pending[len(pending)-1]; !last[1].Equal(wantEnd) {
+				t.Errorf("last period ends %v, want %v", last[1], wantEnd)
+			}
+		})
+	}
+}
+
+func TestSnapshotClosedPeriods_Values(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	attendanceRepo := &fakeRankingRepo{}
+	attend := func(userID uint, from, to time.Time) {
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			attendanceRepo.dailies = append(attendanceRepo.dailies, domain.DailyAttendance{UserID: userID, AttendanceDate: d})
+		}
+	}
+	// user 1 は前の週から続けて今日まで出席、user 2 は週の前半のみ
+	attend(1, day(5, 4), day(5, 20))
+	attend(2, day(5, 11), day(5, 13))
+	repo := &fakeSnapshotRepo{
+		periods: []domain.RankingSnapshotPeriod{{Period: RankingPeriodMonthly, PeriodStart: day(4, 1)}},
+		totals: map[string][]domain.UserRanking{
+			"2026-05-11": {
+				{UserID: 1, TotalDuration: 420, AttendanceDays: 7, TotalPoints: 7},
+				{UserID: 2, TotalDuration: 600, AttendanceDays: 3, TotalPoints: 30},
+			},
+		},
+	}
+	s := newTestSnapshotService(repo, attendanceRepo)
+
+	if _, err := s.SnapshotClosedPeriods(context.Background(), time.Date(2026, 5, 20, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 連続出席は今日ではなく週の最終日（5/17）時点で、前の週から続く分も数える
+	want := map[string]map[uint]int{
+		RankingMetricMinutes:       {1: 420, 2: 600},
+		RankingMetricDays:          {1: 7, 2: 3},
+		RankingMetricCurrentStreak: {1: 14},
+		RankingMetricLongestStreak: {1: 14, 2: 3},
+		RankingMetricPoints:        {1: 7, 2: 30},
+	}
+	got := make(map[string]map[uint]int)
+	for _, sn := range repo.snapshots {
+		if !sn.PeriodStart.Equal(day(5, 11)) || !sn.PeriodEnd.Equal(day(5, 17)) {
+			t.Fatalf("snapshot period = %v〜%v", sn.PeriodStart, sn.PeriodEnd)
+		}
+		if got[sn.Metric] == nil {
+			got[sn.Metric] = make(map[uint]int)
+		}
+		got[sn.Metric][sn.UserID] = sn.Value
+	}
+	for metric, values := range want {
+		if len(got[metric]) != len(values) {
+			t.Errorf("%s = %v, want %v", metric, got[metric], values)
+			continue
+		}
+		for userID, v := range values {
+			if got[metric][userID] != v {
+				t.Errorf("%s user %d = %d, want %d", metric, userID, got[metric][userID], v)
+			}
+		}
+	}
+}
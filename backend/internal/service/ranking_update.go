@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+	"github.com/kasa021/watabe-lab-app/internal/ws"
+)
+
+// RankingUpdate ranking_update イベントのペイロード。前回の配信から変わった行のうち、閲覧者に公開されているものだけを含む
+type RankingUpdate struct {
+	Period string        `json:"period"`
+	Metric string        `json:"metric"`
+	Range  *RankingRange `json:"range"`
+	// Changed 値か順位が変わった行。PreviousRank と RankDelta は前回の配信時の順位との差
+	Changed []domain.UserRanking `json:"changed"`
+	// Removed ランキングから外れたユーザー
+	Removed []uint `json:"removed"`
+	// Masked 閲覧者に公開されていないユーザーの行も変わった。どの行か分からないため、反映するにはランキングを取得し直す
+	Masked bool `json:"masked"`
+}
+
+// RankingUpdateNotifier 今週・今月のランキングの変化を WebSocket で配信する
+type RankingUpdateNotifier interface {
+	// Seed は現在のランキングを配信済みとして記録する。起動時に呼び、最初の配信で全ての行を送らないようにする
+	Seed(ctx context.Context) error
+	// NotifyChanges は今週・今月のランキングを前回の配信と比べ、変わった分を ranking_update イベントで
+	// ログイン中のユーザーごとに配信する（未ログインの接続には送らない）
+	NotifyChanges(ctx context.Context) error
+}
+
+// rankingUpdateSender ranking_update の送り先（*ws.Hub）
+type rankingUpdateSender interface {
+	UserIDs() []uint
+	SendToUser(userID uint, msg interface{})
+}
+
+// rankingUpdatePeriods ranking_update を配信する期間
+var rankingUpdatePeriods = []string{RankingPeriodWeekly, RankingPeriodMonthly}
+
+type publishedRanking struct {
+	from     string
+	rankings map[uint]domain.UserRanking
+}
+
+// rankingChange 1つの期間・指標のランキングの前回の配信からの変化
+type rankingChange struct {
+	period  string
+	metric  string
+	rng     *RankingRange
+	changed []domain.UserRanking
+	removed []domain.UserRanking
+}
+
+type rankingUpdateNotifier struct {
+	rankingService RankingService
+	userRepo       repository.UserRepository
+	hub            rankingUpdateSender
+
+	mu sync.Mutex
+	// last 前回配信したランキング（期間・指標ごと）
+	last map[[2]string]publishedRanking
+}
+
+func NewRankingUpdateNotifier(rankingService RankingService, userRepo repository.UserRepository, hub *ws.Hub) RankingUpdateNotifier {
+	return newRankingUpdateNotifier(rankingService, userRepo, hub)
+}
+
+func newRankingUpdateNotifier(rankingService RankingService, userRepo repository.UserRepository, hub rankingUpdateSender) *rankingUpdateNotifier {
+	return &rankingUpdateNotifier{
+		rankingService: rankingService,
+		userRepo:       userRepo,
+		hub:            hub,
+		last:           make(map[[2]string]publishedRanking),
+	}
+}
+
+func (n *rankingUpdateNotifier) Seed(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := n.collectChanges(ctx)
+	return err
+}
+
+func (n *rankingUpdateNotifier) NotifyChanges(ctx context.Context) error {
+	// 同時に呼ばれた場合に同じ変化を二重に配信しないよう、比較と配信は順番に行う
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	changes, err := n.collectChanges(ctx)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	// 公開範囲は閲覧者によって違うため、ログイン中のユーザーごとに伏せてから送る
+	for _, viewerID := range n.hub.UserIDs() {
+		viewer, err := n.userRepo.FindByID(viewerID)
+		if err != nil {
+			log.Printf("failed to load ranking update viewer %d: %v", viewerID, err)
+			continue
+		}
+		for _, c := range changes {
+			n.hub.SendToUser(viewerID, map[string]interface{}{
+				"type":    "ranking_update",
+				"payload": c.visibleTo(viewer),
+			})
+		}
+	}
+	return nil
+}
+
+// collectChanges 今週・今月のランキングを取得して配信済みとして記録し、前回の記録からの変化を返す。
+// 記録が無い期間・指標（起動直後）は記録するだけにする
+func (n *rankingUpdateNotifier) collectChanges(ctx context.Context) ([]rankingChange, error) {
+	var changes []rankingChange
+	for _, period := range rankingUpdatePeriods {
+		for _, metric := range rankingMetrics {
+			result, err := n.rankingService.GetRanking(ctx, RankingQuery{Period: period, Metric: metric})
+			if err != nil {
+				return nil, err
+			}
+
+			key := [2]string{period, metric}
+			prev, seeded := n.last[key]
+			current := make(map[uint]domain.UserRanking, len(result.Rankings))
+			for _, r := range result.Rankings {
+				current[r.UserID] = r
+			}
+			n.last[key] = publishedRanking{from: result.Range.From, rankings: current}
+			if !seeded {
+				continue
+			}
+
+			if prev.from != result.Range.From {
+				// 期間が変わった
+				prev.rankings = nil
+			}
+			changed, removed := diffRankings(prev.rankings, result.Rankings)
+			if len(changed) == 0 && len(removed) == 0 {
+				continue
+			}
+			change := rankingChange{period: period, metric: metric, rng: result.Range, changed: changed}
+			for _, userID := range removed {
+				change.removed = append(change.removed, prev.rankings[userID])
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// visibleTo 閲覧者に公開されていないユーザーの行と ID を除いた ranking_update のペイロードを返す
+func (c rankingChange) visibleTo(viewer *domain.User) RankingUpdate {
+	update := RankingUpdate{Period: c.period, Metric: c.metric, Range: c.rng}
+	for _, r := range c.changed {
+		if !rankingVisibleTo(viewer, r) {
+			update.Masked = true
+			continue
+		}
+		update.Changed = append(update.Changed, r)
+	}
+	for _, r := range c.removed {
+		if !rankingVisibleTo(viewer, r) {
+			update.Masked = true
+			continue
+		}
+		update.Removed = append(update.Removed, r.UserID)
+	}
+	return update
+}
+
+// diffRankings 前回のランキング prev から値か順位が変わった行と、外れたユーザーを返す。
+// 変わった行には前回の順位からの変化を付ける
+func diffRankings(prev map[uint]domain.UserRanking, current []domain.UserRanking) ([]domain.UserRanking, []uint) {
+	var changed []domain.UserRanking
+	seen := make(map[uint]bool, len(current))
+	for _, r := range current {
+		seen[r.UserID] = true
+		before, ok := prev[r.UserID]
+		if ok && before.Rank == r.Rank && before.Value == r.Value {
+			continue
+		}
+		r.PreviousRank, r.RankDelta = nil, nil
+		if ok {
+			prevRank := before.Rank
+			delta := prevRank - r.Rank
+			r.PreviousRank, r.RankDelta = &prevRank, &delta
+		}
+		changed = append(changed, r)
+	}
+
+	var removed []uint
+	for userID := range prev {
+		if !seen[userID] {
+			removed = append(removed, userID)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	return changed, removed
+}
+
+// MaskPrivateRankings 閲覧者に公開されていないユーザーの表示名と ID を伏せる（順位と時間は残す）。
+// ID は在室者一覧等にも出るため、残すと誰の行か分かってしまう
+func MaskPrivateRankings(viewer *domain.User, rankings []domain.UserRanking) {
+	for i := range rankings {
+		if rankingVisibleTo(viewer, rankings[i]) {
+			continue
+		}
+		rankings[i].IsPrivate = true
+		rankings[i].UserID = 0
+		rankings[i].DisplayName = "非公開ユーザー"
+		rankings[i].Username = ""
+	}
+}
+
+// rankingVisibleTo ランキングの行のユーザーが閲覧者に公開されているか
+func rankingVisibleTo(viewer *domain.User, r domain.UserRanking) bool {
+	target := &domain.User{ID: r.UserID, ProfileVisibility: r.ProfileVisibility}
+	return target.IsVisibleTo(viewer)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+// fakeRankingResults 今週の在室時間のランキングだけを返す RankingService
+type fakeRankingResults struct {
+	RankingService
+	weekly []domain.UserRanking
+}
+
+func (s *fakeRankingResults) GetRanking(ctx context.Context, query RankingQuery) (*RankingResult, error) {
+	result := &RankingResult{Period: query.Period, Metric: query.Metric, Range: &RankingRange{From: "2026-04-27", To: "2026-05-03"}}
+	if query.Period == RankingPeriodWeekly && query.Metric == rankingMetrics[0] {
+		result.Rankings = s.weekly
+	}
+	return result, nil
+}
+
+// sentRankingUpdate SendToUser で送った ranking_update
+type sentRankingUpdate struct {
+	userID uint
+	update RankingUpdate
+}
+
+// fakeRankingUpdateSender 接続中のユーザーと送ったメッセージを持つ rankingUpdateSender
+type fakeRankingUpdateSender struct {
+	userIDs []uint
+	sent    []sentRankingUpdate
+}
+
+func (s *fakeRankingUpdateSender) UserIDs() []uint { return s.userIDs }
+
+func (s *fakeRankingUpdateSender) SendToUser(userID uint, msg interface{}) {
+	payload := msg.(map[string]interface{})["payload"].(RankingUpdate)
+	s.sent = append(s.sent, sentRankingUpdate{userID: userID, update: payload})
+}
+
+func TestRankingUpdateNotifier_NotifyChanges(t *testing.T) {
+	rankings := &fakeRankingResults{weekly: []domain.UserRanking{
+		{UserID: 1, ProfileVisibility: domain.VisibilityPublic, Rank: 1, Value: 300},
+		{UserID: 2, ProfileVisibility: domain.VisibilityPrivate, Rank: 2, Value: 200},
+		{UserID: 3, ProfileVisibility: domain.VisibilityPrivate, Rank: 3, Value: 100},
+	}}
+	users := fakeUserRepo{users: map[uint]domain.User{
+		4: {ID: 4, Role: "student", IsActive: true},
+		9: {ID: 9, Role: "teacher", IsActive: true},
+	}}
+	sender := &fakeRankingUpdateSender{userIDs: []uint{4, 9}}
+	n := newRankingUpdateNotifier(rankings, users, sender)
+
+	// 起動時に記録した分は配信しない
+	if err := n.Seed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.NotifyChanges(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("変化が無いのに配信した: %+v", sender.sent)
+	}
+
+	// user 1 の時間が増え、非公開の user 2 が順位を上げ、非公開の user 3 が外れた
+	rankings.weekly = []domain.UserRanking{
+		{UserID: 2, ProfileVisibility: domain.VisibilityPrivate, Rank: 1, Value: 400},
+		{UserID: 1, ProfileVisibility: domain.VisibilityPublic, Rank: 2, Value: 350},
+	}
+	if err := n.NotifyChanges(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 2 {
+		t.Fatalf("配信: got %+v", sender.sent)
+	}
+
+	student := sender.sent[0]
+	if student.userID != 4 || !student.update.Masked || len(student.update.Changed) != 1 || student.update.Changed[0].UserID != 1 || len(student.update.Removed) != 0 {
+		t.Errorf("非公開のユーザーが学生に配信された: %+v", student)
+	}
+	teacher := sender.sent[1]
+	if teacher.userID != 9 || teacher.update.Masked || len(teacher.update.Changed) != 2 || len(teacher.update.Removed) != 1 || teacher.update.Removed[0] != 3 {
+		t.Errorf("教員への配信: %+v", teacher)
+	}
+}
+
+func TestRankingUpdateNotifier_FirstNotifyOnlyRecords(t *testing.T) {
+	rankings := &fakeRankingResults{weekly: []domain.UserRanking{
+		{UserID: 1, ProfileVisibility: domain.VisibilityPublic, Rank: 1, Value: 300},
+	}}
+	sender := &fakeRankingUpdateSender{userIDs: []uint{1}}
+	n := newRankingUpdateNotifier(rankings, fakeUserRepo{users: map[uint]domain.User{1: {ID: 1, IsActive: true}}}, sender)
+
+	// Seed に失敗していても、前回の記録が無い状態から全ての行を送らない
+	if err := n.NotifyChanges(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 0 {
+		t.Errorf("記録が無いのに配信した: %+v", sender.sent)
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+)
+
+var ErrInvalidSessionQuery = errors.New("invalid session query")
+
+// checkInMethodPattern check_in_method の絞り込みに指定できる値（check_in_logs.check_in_method は VARCHAR(20)）
+var checkInMethodPattern = regexp.MustCompile(`^[a-z_]{1,20}$`)
+
+const (
+	defaultSessionPageSize = 20
+	maxSessionPageSize     = 100
+)
+
+// SessionHistoryQuery セッション履歴の取得条件（日付は YYYY-MM-DD、To の日を含む）
+type SessionHistoryQuery struct {
+	From          string
+	To            string
+	CheckInMethod string
+	Cursor        string
+	Limit         int
+}
+
+// SessionHistory セッション履歴の1ページ分
+type SessionHistory struct {
+	Sessions []domain.CheckInLog `json:"sessions"`
+	// NextCursor 次のページを取得するためのカーソル（最終ページの場合は空）
+	NextCursor string                    `json:"next_cursor"`
+	Totals     *repository.SessionTotals `json:"totals"`
+}
+
+func (s *attendanceService) GetSessionHistory(ctx context.Context, userID uint, query SessionHistoryQuery) (*SessionHistory, error) {
+	filter, err := s.sessionFilter(userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// 次ページの有無を判定するため1件多く取得する
+	filter.Limit++
+	sessions, err := s.repo.ListSessions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit--
+
+	history := &SessionHistory{Sessions: sessions}
+	if len(sessions) > filter.Limit {
+		history.Sessions = sessions[:filter.Limit]
+		history.NextCursor = encodeSessionCursor(history.Sessions[filter.Limit-1])
+	}
+	if history.Sessions == nil {
+		history.Sessions = []domain.CheckInLog{}
+	}
+
+	history.Totals, err = s.repo.SumSessions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (s *attendanceService) sessionFilter(userID uint, query SessionHistoryQuery) (repository.SessionFilter, error) {
+	filter := repository.SessionFilter{
+		UserID:        userID,
+		CheckInMethod: query.CheckInMethod,
+		Limit:         query.Limit,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSessionPageSize
+	}
+	if filter.Limit > maxSessionPageSize {
+		filter.Limit = maxSessionPageSize
+	}
+
+	if query.CheckInMethod != "" && !checkInMethodPattern.MatchString(query.CheckInMethod) {
+		return filter, fmt.Errorf("check_in_method: %w", ErrInvalidSessionQuery)
+	}
+
+	if query.From != "" {
+		from, err := time.ParseInLocation("2006-01-02", query.From, s.loc)
+		if err != nil {
+			return filter, fmt.Errorf("from: %w", ErrInvalidSessionQuery)
+		}
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", query.To, s.loc)
+		if err != nil {
+			return filter, fmt.Errorf("to: %w", ErrInvalidSessionQuery)
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, fmt.Errorf("from must not be after to: %w", ErrInvalidSessionQuery)
+	}
+
+	if query.Cursor != "" {
+		checkInAt, id, err := decodeSessionCursor(query.Cursor)
+		if err != nil {
+			return filter, fmt.Errorf("cursor: %w", ErrInvalidSessionQuery)
+		}
+		filter.AfterCheckInAt = &checkInAt
+		filter.AfterID = id
+	}
+	return filter, nil
+}
+
+// encodeSessionCursor セッションの (check_in_at, id) を不透明なカーソル文字列にする
+func encodeSessionCursor(log domain.CheckInLog) string {
+	raw := log.CheckInAt.Format(time.RFC3339Nano) + "|" + strconv.FormatUint(uint64(log.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSessionCursor(cursor string) (time.Time, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, 0, errors.New("malformed cursor")
+	}
+	checkInAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return checkInAt, uint(id), nil
+}
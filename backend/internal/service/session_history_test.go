@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+	"github.com/kasa021/watabe-lab-app/internal/repository"
+)
+
+// fakeHistoryRepo セッション履歴の検索だけを持つ AttendanceRepository。受け取った条件を記録する
+type fakeHistoryRepo struct {
+	repository.AttendanceRepository
+	sessions []domain.CheckInLog
+	filters  []repository.SessionFilter
+}
+
+// matches カーソル以外の条件に一致するか
+func (r *fakeHistoryRepo) matches(l domain.CheckInLog, filter repository.SessionFilter) bool {
+	return l.UserID == filter.UserID &&
+		(filter.From == nil || !l.CheckInAt.Before(*filter.From)) &&
+		(filter.To == nil || l.CheckInAt.Before(*filter.To)) &&
+		(filter.CheckInMethod == "" || l.CheckInMethod == filter.CheckInMethod)
+}
+
+func (r *fakeHistoryRepo) ListSessions(ctx context.Context, filter repository.SessionFilter) ([]domain.CheckInLog, error) {
+	r.filters = append(r.filters, filter)
+	var logs []domain.CheckInLog
+	for _, l := range r.sessions {
+		if !r.matches(l, filter) {
+			continue
+		}
+		if after := filter.AfterCheckInAt; after != nil && !(l.CheckInAt.Before(*after) || (l.CheckInAt.Equal(*after) && l.ID < filter.AfterID)) {
+			continue
+		}
+		logs = append(logs, l)
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if !logs[i].CheckInAt.Equal(logs[j].CheckInAt) {
+			return logs[i].CheckInAt.After(logs[j].CheckInAt)
+		}
+		return logs[i].ID > logs[j].ID
+	})
+	if len(logs) > filter.Limit {
+		logs = logs[:filter.Limit]
+	}
+	return logs, nil
+}
+
+func (r *fakeHistoryRepo) SumSessions(ctx context.Context, filter repository.SessionFilter) (*repository.SessionTotals, error) {
+	var totals repository.SessionTotals
+	for _, l := range r.sessions {
+		if !r.matches(l, filter) {
+			continue
+		}
+		totals.SessionCount++
+		if l.DurationMinutes != nil {
+			totals.TotalMinutes += int64(*l.DurationMinutes)
+		}
+	}
+	return &totals, nil
+}
+
+func newHistoryService(sessions ...domain.CheckInLog) (*attendanceService, *fakeHistoryRepo) {
+	repo := &fakeHistoryRepo{sessions: sessions}
+	return &attendanceService{repo: repo, loc: time.UTC}, repo
+}
+
+func TestGetSessionHistory_Pagination(t *testing.T) {
+	at := func(d, hour int) time.Time { return time.Date(2026, 5, d, hour, 0, 0, 0, time.UTC) }
+	var sessions []domain.CheckInLog
+	// 5/1〜5/5 に1時間ずつ。5/3 は同じ時刻のセッションが2つある
+	for i, d := range []int{1, 2, 3, 3, 4, 5} {
+		l := closedLog(1, at(d, 10), at(d, 11))
+		l.ID = uint(i + 1)
+		sessions = append(sessions, l)
+	}
+	// 他のユーザーのセッションは含まない
+	sessions = append(sessions, closedLog(2, at(3, 10), at(3, 11)))
+	s, _ := newHistoryService(sessions...)
+
+	var ids []uint
+	cursor := ""
+	for page := 0; ; page++ {
+		history, err := s.GetSessionHistory(context.Background(), 1, SessionHistoryQuery{From: "2026-05-02", Cursor: cursor, Limit: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 合計はカーソルに関係なく絞り込んだ範囲全体
+		if history.Totals.SessionCount != 5 || history.Totals.TotalMinutes != 300 {
+			t.Errorf("page %d totals = %+v, want 5 sessions / 300 minutes", page, history.Totals)
+		}
+		for _, l := range history.Sessions {
+			ids = append(ids, l.ID)
+		}
+		if history.NextCursor == "" {
+			break
+		}
+		if page > 3 {
+			t.Fatal("pagination did not end")
+		}
+		cursor = history.NextCursor
+	}
+
+	want := []uint{6, 5, 4, 3, 2}
+	if len(ids) != len(want) {
+		t.Fatalf("ids = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ids = %v, want %v", ids, want)
+		}
+	}
+}
+
+func TestGetSessionHistory_LastPageHasNoCursor(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 5, 1, hour, 0, 0, 0, time.UTC) }
+	sessions := []domain.CheckInLog{closedLog(1, at(9), at(10)), closedLog(1, at(11), at(12))}
+	sessions[0].ID, sessions[1].ID = 1, 2
+	s, _ := newHistoryService(sessions...)
+
+	// 件数がちょうど limit の場合は次のページは無い
+	history, err := s.GetSessionHistory(context.Background(), 1, SessionHistoryQuery{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Sessions) != 2 || history.NextCursor != "" {
+		t.Errorf("sessions = %d, next_cursor = %q", len(history.Sessions), history.NextCursor)
+	}
+
+	empty, err := s.GetSessionHistory(context.Background(), 9, SessionHistoryQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Sessions == nil || len(empty.Sessions) != 0 {
+		t.Errorf("sessions = %#v, want empty slice", empty.Sessions)
+	}
+}
+
+func TestGetSessionHistory_LimitClamp(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{0, defaultSessionPageSize},
+		{-5, defaultSessionPageSize},
+		{30, 30},
+		{1000, maxSessionPageSize},
+	}
+	for _, tt := range tests {
+		s, repo := newHistoryService()
+		if _, err := s.GetSessionHistory(context.Background(), 1, SessionHistoryQuery{Limit: tt.limit}); err != nil {
+			t.Fatal(err)
+		}
+		// 次ページの有無を判定するため1件多く取得する
+		if got := repo.filters[0].Limit; got != tt.want+1 {
+			t.Errorf("limit %d: fetched %d, want %d", tt.limit, got, tt.want+1)
+		}
+	}
+}
+
+func TestGetSessionHistory_Filters(t *testing.T) {
+	s, repo := newHistoryService()
+	if _, err := s.GetSessionHistory(context.Background(), 1, SessionHistoryQuery{From: "2026-05-01", To: "2026-05-01", CheckInMethod: "web_manual"}); err != nil {
+		t.Fatal(err)
+	}
+	// to の日を含む
+	filter := repo.filters[0]
+	if !filter.From.Equal(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)) || !filter.To.Equal(time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)) || filter.CheckInMethod != "web_manual" {
+		t.Errorf("filter = %+v", filter)
+	}
+}
+
+func TestGetSessionHistory_InvalidQuery(t *testing.T) {
+	validCursor := encodeSessionCursor(domain.CheckInLog{ID: 3, CheckInAt: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)})
+	tests := []struct {
+		name  string
+		query SessionHistoryQuery
+	}{
+		{"from が日付でない", SessionHistoryQuery{From: "2026/05/01"}},
+		{"to が日付でない", SessionHistoryQuery{To: "yesterday"}},
+		{"from が to より後", SessionHistoryQuery{From: "2026-05-02", To: "2026-05-01"}},
+		{"check_in_method が不正", SessionHistoryQuery{CheckInMethod: "wifi' OR 1=1"}},
+		{"check_in_method が長すぎる", SessionHistoryQuery{CheckInMethod: "a_very_long_check_in_method"}},
+		{"カーソルが base64 でない", SessionHistoryQuery{Cursor: "!!!"}},
+		{"カーソルの区切りが無い", SessionHistoryQuery{Cursor: base64.RawURLEncoding.EncodeToString([]byte("2026-05-01T09:00:00Z"))}},
+		{"カーソルの時刻が不正", SessionHistoryQuery{Cursor: base64.RawURLEncoding.EncodeToString([]byte("yesterday|3"))}},
+		{"カーソルの ID が不正", SessionHistoryQuery{Cursor: base64.RawURLEncoding.EncodeToString([]byte("2026-05-01T09:00:00Z|-1"))}},
+		{"カーソルが書き換えられた", SessionHistoryQuery{Cursor: validCursor[:len(validCursor)-2] + "!"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newHistoryService()
+			if _, err := s.GetSessionHistory(context.Background(), 1, tt.query); !errors.Is(err, ErrInvalidSessionQuery) {
+				t.Errorf("err = %v, want %v", err, ErrInvalidSessionQuery)
+			}
+			if len(repo.filters) != 0 {
+				t.Error("invalid query reached the repository")
+			}
+		})
+	}
+}
+
+func TestSessionCursor_RoundTrip(t *testing.T) {
+	checkInAt := time.Date(2026, 5, 1, 9, 30, 15, 123456789, time.UTC)
+	gotAt, gotID, err := decodeSessionCursor(encodeSessionCursor(domain.CheckInLog{ID: 42, CheckInAt: checkInAt}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gotAt.Equal(checkInAt) || gotID != 42 {
+		t.Errorf("got %v %d, want %v 42", gotAt, gotID, checkInAt)
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // tzdata が無い環境でもテストできるようにする
+
+	"github.com/kasa021/watabe-lab-app/internal/domain"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("タイムゾーン %s を読み込めません: %v", name, err)
+	}
+	return loc
+}
+
+func closedLog(userID uint, checkIn, checkOut time.Time) domain.CheckInLog {
+	duration := int(checkOut.Sub(checkIn).Minutes())
+	return domain.CheckInLog{
+		UserID:          userID,
+		CheckInAt:       checkIn,
+		CheckOutAt:      &checkOut,
+		DurationMinutes: &duration,
+	}
+}
+
+func TestWeekRange(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name  string
+		now   time.Time
+		loc   *time.Location
+		start time.Time
+	}{
+		// UTC では日曜だが研究室では月曜
+		{"日付の境界", time.Date(2026, 3, 15, 16, 0, 0, 0, time.UTC), tokyo, time.Date(2026, 3, 16, 0, 0, 0, 0, tokyo)},
+		{"日曜の深夜", time.Date(2026, 3, 15, 23, 59, 0, 0, tokyo), tokyo, time.Date(2026, 3, 9, 0, 0, 0, 0, tokyo)},
+		// 2026-03-08 に夏時間が始まる週
+		{"夏時間の開始", time.Date(2026, 3, 8, 12, 0, 0, 0, newYork), newYork, time.Date(2026, 3, 2, 0, 0, 0, 0, newYork)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := weekRange(tt.now, tt.loc)
+			if !start.Equal(tt.start) {
+				t.Errorf("週の開始: got %v, want %v", start, tt.start)
+			}
+			if want := tt.start.AddDate(0, 0, 7); !end.Equal(want) {
+				t.Errorf("週の終了: got %v, want %v", end, want)
+			}
+		})
+	}
+}
+
+func TestMonthRange(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+
+	// UTC では1月31日だが研究室では2月1日
+	start, end := monthRange(time.Date(2026, 1, 31, 15, 30, 0, 0, time.UTC), tokyo)
+	if want := time.Date(2026, 2, 1, 0, 0, 0, 0, tokyo); !start.Equal(want) {
+		t.Errorf("月の開始: got %v, want %v", start, want)
+	}
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, tokyo); !end.Equal(want) {
+		t.Errorf("月の終了: got %v, want %v", end, want)
+	}
+}
+
+func TestSplitByDay(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	tests := []struct {
+		name     string
+		checkIn  time.Time
+		checkOut time.Time
+		loc      *time.Location
+		want     []int
+	}{
+		{"日をまたぐ", time.Date(2026, 4, 1, 23, 0, 0, 0, tokyo), time.Date(2026, 4, 2, 1, 30, 0, 0, tokyo), tokyo, []int{60, 90}},
+		{"0:00ちょうどに退室", time.Date(2026, 4, 1, 22, 0, 0, 0, tokyo), time.Date(2026, 4, 2, 0, 0, 0, 0, tokyo), tokyo, []int{120}},
+		{"UTCで渡された時刻", time.Date(2026, 4, 1, 14, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 16, 0, 0, 0, time.UTC), tokyo, []int{60, 60}},
+		// 2:00 が 3:00 になるため、0:00〜4:00 の実時間は3時間
+		{"夏時間の開始", time.Date(2026, 3, 7, 22, 0, 0, 0, newYork), time.Date(2026, 3, 8, 4, 0, 0, 0, newYork), newYork, []int{120, 180}},
+		// 2:00 が 1:00 に戻るため、0:00〜3:00 の実時間は4時間
+		{"夏時間の終了", time.Date(2026, 10, 31, 23, 0, 0, 0, newYork), time.Date(2026, 11, 1, 3, 0, 0, 0, newYork), newYork, []int{60, 240}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duration := int(tt.checkOut.Sub(tt.checkIn).Minutes())
+			slices := splitByDay(tt.checkIn, tt.checkOut, duration, tt.loc)
+			if len(slices) != len(tt.want) {
+				t.Fatalf("日数: got %d, want %d (%v)", len(slices), len(tt.want), slices)
+			}
+			for i, want := range tt.want {
+				if slices[i].minutes != want {
+					t.Errorf("%d日目の滞在時間: got %d, want %d", i+1, slices[i].minutes, want)
+				}
+				if h, m, _ := slices[i].date.Clock(); h != 0 || m != 0 {
+					t.Errorf("%d日目の日付が0:00ではありません: %v", i+1, slices[i].date)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildDailyAttendances_MidnightCheckOut(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+
+	dailies := buildDailyAttendances([]domain.CheckInLog{
+		closedLog(1, time.Date(2026, 4, 1, 22, 0, 0, 0, tokyo), time.Date(2026, 4, 2, 0, 0, 0, 0, tokyo)),
+	}, tokyo, nil)
+
+	if len(dailies) != 1 {
+		t.Fatalf("0:00ちょうどの退室で翌日の行が作られています: %+v", dailies)
+	}
+	d := dailies[0]
+	if want := time.Date(2026, 4, 1, 0, 0, 0, 0, tokyo); !d.AttendanceDate.Equal(want) {
+		t.Errorf("日付: got %v, want %v", d.AttendanceDate, want)
+	}
+	if d.TotalDurationMinutes != 120 || d.CheckInCount != 1 || d.LastCheckOutAt == nil {
+		t.Errorf("集計が不正です: %+v", d)
+	}
+}
+
+func TestAchievementEvaluator_EarlyCheckInUsesLabTimeZone(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+
+	// UTC で保存・取得された時刻でも、研究室の時刻で「10:00前」を判定する
+	logs := []domain.CheckInLog{
+		closedLog(1, time.Date(2026, 4, 1, 0, 30, 0, 0, time.UTC), time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)), // 9:30
+		closedLog(1, time.Date(2026, 4, 2, 0, 59, 0, 0, time.UTC), time.Date(2026, 4, 2, 8, 0, 0, 0, time.UTC)), // 9:59
+		closedLog(1, time.Date(2026, 4, 3, 1, 0, 0, 0, time.UTC), time.Date(2026, 4, 3, 8, 0, 0, 0, time.UTC)),  // 10:00
+		// 前日 23:30 に来て日をまたいで滞在した日は、その日の最初のチェックインがないので数えない
+		closedLog(1, time.Date(2026, 4, 3, 14, 30, 0, 0, time.UTC), time.Date(2026, 4, 3, 17, 0, 0, 0, time.UTC)),
+	}
+	e := newAchievementEvaluator(logs, tokyo, nil, HolidayRules{})
+
+	progress, err := e.Evaluate(domain.Achievement{
+		Code:           "early_bird",
+		ConditionType:  ConditionEarlyCheckIn,
+		ConditionValue: domain.JSONB{"days": float64(3), "time": "10:00:00"},
+	})
+	if err != nil {
+		t.Fatalf("評価に失敗しました: %v", err)
+	}
+	if progress.Current != 2 {
+		t.Errorf("連続日数: got %d, want 2", progress.Current)
+	}
+
+	streak, err := e.Evaluate(domain.Achievement{
+		Code:           "streak",
+		ConditionType:  ConditionStreakDays,
+		ConditionValue: domain.JSONB{"days": float64(4)},
+	})
+	if err != nil {
+		t.Fatalf("評価に失敗しました: %v", err)
+	}
+	if streak.Current != 4 {
+		t.Errorf("連続出席日数: got %d, want 4", streak.Current)
+	}
+}
@@ -41,6 +41,9 @@ type Client struct {
 
 	// Buffered channel of outbound messages.
 	send chan []byte
+
+	// ID of the authenticated user, or 0 for anonymous connections.
+	userID uint
 }
 
 // readPump pumps messages from the websocket connection to the hub.
@@ -106,13 +109,14 @@ func (c *Client) writePump() {
 }
 
 // ServeWs handles websocket requests from the peer.
-func ServeWs(hub *Hub, c *gin.Context) {
+// userID identifies the authenticated user for direct messages (0 if anonymous).
+func ServeWs(hub *Hub, c *gin.Context, userID uint) {
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	client := &Client{hub: hub, conn: conn, send: make(chan []byte, 256)}
+	client := &Client{hub: hub, conn: conn, send: make(chan []byte, 256), userID: userID}
 	client.hub.register <- client
 
 	// Allow collection of memory referenced by the caller by doing all work in
@@ -1,11 +1,16 @@
 package ws
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"sync"
+)
 
 // Hub maintains the set of active clients and broadcasts messages to the
 // clients.
 type Hub struct {
-	// Registered clients.
+	// Registered clients. mu guards clients so that UserIDs can read it
+	// outside the Run goroutine.
+	mu      sync.RWMutex
 	clients map[*Client]bool
 
 	// Inbound messages from the clients.
@@ -16,6 +21,15 @@ type Hub struct {
 
 	// Unregister requests from clients.
 	unregister chan *Client
+
+	// Messages addressed to the clients of a single user.
+	direct chan directMessage
+}
+
+// directMessage is a message delivered only to the clients of userID.
+type directMessage struct {
+	userID  uint
+	payload []byte
 }
 
 func NewHub() *Hub {
@@ -23,6 +37,7 @@ func NewHub() *Hub {
 		broadcast:  make(chan []byte),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		direct:     make(chan directMessage),
 		clients:    make(map[*Client]bool),
 	}
 }
@@ -31,23 +46,61 @@ func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.register:
+			h.mu.Lock()
 			h.clients[client] = true
+			h.mu.Unlock()
 		case client := <-h.unregister:
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-			}
+			h.mu.Lock()
+			h.remove(client)
+			h.mu.Unlock()
 		case message := <-h.broadcast:
+			h.mu.Lock()
 			for client := range h.clients {
 				select {
 				case client.send <- message:
 				default:
-					close(client.send)
-					delete(h.clients, client)
+					h.remove(client)
 				}
 			}
+			h.mu.Unlock()
+		case message := <-h.direct:
+			h.mu.Lock()
+			for client := range h.clients {
+				if client.userID != message.userID {
+					continue
+				}
+				select {
+				case client.send <- message.payload:
+				default:
+					h.remove(client)
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+}
+
+// remove closes and forgets a client. The caller must hold mu.
+func (h *Hub) remove(client *Client) {
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client.send)
+	}
+}
+
+// UserIDs returns the users with at least one authenticated connection.
+func (h *Hub) UserIDs() []uint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	seen := make(map[uint]bool)
+	var ids []uint
+	for client := range h.clients {
+		if client.userID != 0 && !seen[client.userID] {
+			seen[client.userID] = true
+			ids = append(ids, client.userID)
 		}
 	}
+	return ids
 }
 
 // BroadcastMessage sends a JSON encoded message to all connected clients
@@ -57,3 +110,12 @@ func (h *Hub) BroadcastMessage(msg interface{}) {
 		h.broadcast <- bytes
 	}
 }
+
+// SendToUser sends a JSON encoded message to the clients authenticated as userID.
+// Messages for users without an open connection are dropped.
+func (h *Hub) SendToUser(userID uint, msg interface{}) {
+	bytes, err := json.Marshal(msg)
+	if err == nil {
+		h.direct <- directMessage{userID: userID, payload: bytes}
+	}
+}
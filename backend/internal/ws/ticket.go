@@ -0,0 +1,69 @@
+package ws
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TicketStore issues short-lived, single-use tickets for opening a WebSocket.
+// Browsers cannot set headers on a WebSocket handshake, and a JWT in the query
+// string would end up in access logs, so an authenticated API call exchanges
+// the JWT for a ticket that is useless once the connection is opened.
+type TicketStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	tickets map[string]ticket
+	now     func() time.Time
+}
+
+type ticket struct {
+	userID    uint
+	expiresAt time.Time
+}
+
+func NewTicketStore(ttl time.Duration) *TicketStore {
+	return &TicketStore{
+		ttl:     ttl,
+		tickets: make(map[string]ticket),
+		now:     time.Now,
+	}
+}
+
+// Issue creates a ticket for userID that expires after the store's TTL.
+func (s *TicketStore) Issue(userID uint) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	value := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	// Drop tickets that were never redeemed.
+	for k, t := range s.tickets {
+		if !now.Before(t.expiresAt) {
+			delete(s.tickets, k)
+		}
+	}
+	s.tickets[value] = ticket{userID: userID, expiresAt: now.Add(s.ttl)}
+	return value, nil
+}
+
+// Redeem consumes a ticket and returns its user. It reports false if the
+// ticket is unknown, already used or expired.
+func (s *TicketStore) Redeem(value string) (uint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tickets[value]
+	if !ok {
+		return 0, false
+	}
+	delete(s.tickets, value)
+	if !s.now().Before(t.expiresAt) {
+		return 0, false
+	}
+	return t.userID, true
+}
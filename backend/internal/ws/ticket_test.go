@@ -0,0 +1,35 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTicketStore(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewTicketStore(30 * time.Second)
+	store.now = func() time.Time { return now }
+
+	ticket, err := store.Issue(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID, ok := store.Redeem(ticket); !ok || userID != 7 {
+		t.Fatalf("Redeem = %d %v, want 7 true", userID, ok)
+	}
+	if _, ok := store.Redeem(ticket); ok {
+		t.Error("ticket was redeemed twice")
+	}
+
+	expired, err := store.Issue(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(30 * time.Second)
+	if _, ok := store.Redeem(expired); ok {
+		t.Error("expired ticket was redeemed")
+	}
+	if _, ok := store.Redeem("unknown"); ok {
+		t.Error("unknown ticket was redeemed")
+	}
+}
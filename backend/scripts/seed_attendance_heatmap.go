@@ -1,27 +1,23 @@
 package main
 
 import (
-	"fmt"
 	"log"
 	"math/rand"
-	"os"
 	"time"
 
+	"github.com/kasa021/watabe-lab-app/internal/config"
+	"github.com/kasa021/watabe-lab-app/internal/database"
 	"github.com/kasa021/watabe-lab-app/internal/domain"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
 func main() {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Tokyo",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PORT"),
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	cfg := config.Load()
+	labLoc, err := cfg.Lab.Location()
+	if err != nil {
+		log.Fatalf("invalid lab time zone: %v", err)
+	}
+
+	db, err := database.NewDatabase(cfg)
 	if err != nil {
 		log.Fatalf("failed to connect database: %v", err)
 	}
@@ -34,8 +30,8 @@ func main() {
 
 	log.Printf("Seeding attendance data for user: %s (ID: %d)", user.Username, user.ID)
 
-	// 過去1年分
-	now := time.Now()
+	// 過去1年分（入室時刻は研究室のタイムゾーンで生成する）
+	now := time.Now().In(labLoc)
 	oneYearAgo := now.AddDate(-1, 0, 0)
 
 	// 生成パラメータ
@@ -8,8 +8,31 @@ export interface Achievement {
   description: string
   icon_url?: string
   category: string
+  condition_type: string
+  condition_value: Record<string, number | string>
   points_reward: number
   is_active: boolean
+  display_order: number
+}
+
+// 管理者による称号の作成・更新内容（icon_url・is_active・display_order は省略時は変更しない）
+export interface AchievementInput {
+  code: string
+  name: string
+  description: string
+  icon_url?: string
+  category: string
+  condition_type: string
+  condition_value: Record<string, number | string>
+  points_reward: number
+  is_active?: boolean
+  display_order?: number
+}
+
+// アップロードされたアイコンは API と同じオリジンから配信される
+export const achievementIconSrc = (iconUrl?: string) => {
+  if (!iconUrl) return undefined
+  return iconUrl.startsWith('/api/') ? (import.meta.env.VITE_API_BASE_URL ?? '') + iconUrl : iconUrl
 }
 
 export interface UserAchievement {
@@ -17,10 +40,42 @@ export interface UserAchievement {
   user_id: number
   achievement_id: number
   achieved_at: string
+  // 教員・管理者が手動で付与した場合の付与者と理由
+  awarded_by?: number
+  note?: string
   achievement: Achievement
   user?: User
 }
 
+// 称号の手動付与・取り消しの履歴
+export interface AchievementAwardLog {
+  id: number
+  user_id: number
+  achievement_id: number
+  actor_id: number | null
+  action: 'award' | 'revoke'
+  note: string
+  created_at: string
+  achievement: Achievement
+  actor?: User
+}
+
+export interface AchievementStatus {
+  achievement: Achievement
+  current: number
+  target: number
+  percent: number
+  unlocked: boolean
+  achieved_at: string | null
+}
+
+// achievement_unlocked（本人向け）・achievement_announcement（全員向け）イベントのペイロード
+export interface AchievementUnlocked {
+  user_id: number
+  display_name: string
+  achievements: Achievement[]
+}
+
 export const achievementApi = {
   getAchievements: async (): Promise<Achievement[]> => {
     const response = await apiClient.get<{ achievements: Achievement[] }>('/api/v1/achievements')
@@ -31,4 +86,64 @@ export const achievementApi = {
     const response = await apiClient.get<{ user_achievements: UserAchievement[] }>('/api/v1/achievements/my')
     return response.data.user_achievements
   },
+
+  getMyProgress: async (): Promise<AchievementStatus[]> => {
+    const response = await apiClient.get<{ progress: AchievementStatus[] }>('/api/v1/achievements/my/progress')
+    return response.data.progress
+  },
+
+  // 以下は管理者・教員のみ
+  adminList: async (): Promise<Achievement[]> => {
+    const response = await apiClient.get<{ achievements: Achievement[] }>('/api/v1/admin/achievements')
+    return response.data.achievements
+  },
+
+  // 称号マスタの編集（作成・更新・削除・並べ替え・アイコン）は管理者のみ
+  adminCreate: async (input: AchievementInput): Promise<Achievement> => {
+    const response = await apiClient.post<{ achievement: Achievement }>('/api/v1/admin/achievements', input)
+    return response.data.achievement
+  },
+
+  adminUpdate: async (id: number, input: AchievementInput): Promise<Achievement> => {
+    const response = await apiClient.put<{ achievement: Achievement }>(`/api/v1/admin/achievements/${id}`, input)
+    return response.data.achievement
+  },
+
+  adminDelete: async (id: number, force = false): Promise<void> => {
+    await apiClient.delete(`/api/v1/admin/achievements/${id}`, { params: force ? { force: true } : undefined })
+  },
+
+  adminReorder: async (ids: number[]): Promise<Achievement[]> => {
+    const response = await apiClient.put<{ achievements: Achievement[] }>('/api/v1/admin/achievements/order', { ids })
+    return response.data.achievements
+  },
+
+  adminUploadIcon: async (id: number, icon: File): Promise<Achievement> => {
+    const form = new FormData()
+    form.append('icon', icon)
+    const response = await apiClient.post<{ achievement: Achievement }>(`/api/v1/admin/achievements/${id}/icon`, form, {
+      headers: { 'Content-Type': 'multipart/form-data' },
+    })
+    return response.data.achievement
+  },
+
+  // condition_type が manual の称号のみ付与・取り消しできる
+  adminAward: async (achievementId: number, userId: number, note: string): Promise<UserAchievement> => {
+    const response = await apiClient.post<{ user_achievement: UserAchievement }>(
+      `/api/v1/admin/achievements/${achievementId}/awards`,
+      { user_id: userId, note }
+    )
+    return response.data.user_achievement
+  },
+
+  adminRevoke: async (achievementId: number, userId: number, note: string): Promise<void> => {
+    await apiClient.delete(`/api/v1/admin/achievements/${achievementId}/awards/${userId}`, { data: { note } })
+  },
+
+  adminGetAwardLogs: async (userId?: number): Promise<AchievementAwardLog[]> => {
+    const response = await apiClient.get<{ award_logs: AchievementAwardLog[] }>('/api/v1/admin/achievements/award-logs', {
+      params: userId ? { user_id: userId } : undefined,
+    })
+    return response.data.award_logs
+  },
 }
@@ -1,18 +1,128 @@
 import { apiClient } from './client'
 
 
+export type RankingMetric = 'minutes' | 'days' | 'current_streak' | 'longest_streak' | 'points'
+
+export type RankingPeriod = 'weekly' | 'monthly' | 'total' | 'custom' | 'term'
+
 export interface UserRanking {
   user_id: number
   display_name: string
   username: string
   total_duration: number
+  attendance_days: number
+  total_points: number
+  // 閲覧者に公開されていないユーザー（表示名を伏せ、user_id は 0 で返る）
+  is_private: boolean
+  metric: RankingMetric
+  value: number
+  rank: number
+  previous_rank?: number
+  rank_delta?: number
+}
+
+export interface RankingRange {
+  from: string
+  to: string
+}
+
+export interface RankingResult {
+  period: RankingPeriod
+  metric: RankingMetric
+  range?: RankingRange
+  previous?: RankingRange
+  rankings: UserRanking[]
+}
+
+export interface RankingOptions {
+  from?: string
+  to?: string
+  term?: string
+  year?: number
+  compare?: boolean
+}
+
+export interface RankingHistoryEntry {
+  from: string
+  to: string
+  rank: number
+  value: number
+}
+
+export type SnapshotPeriod = 'weekly' | 'monthly'
+
+// WebSocket の ranking_update イベント（前回の配信から変わった行のうち、自分に公開されているもののみ）
+export interface RankingUpdate {
+  period: SnapshotPeriod
+  metric: RankingMetric
+  range: RankingRange
+  changed: UserRanking[] | null
+  removed: number[] | null
+  // 公開されていないユーザーの行も変わった（どの行かは分からないため取得し直す）
+  masked: boolean
+}
+
+// 取得済みのランキングに ranking_update の変化を反映する。
+// 伏せられた行（user_id が 0）は変わっていないため、そのまま残す
+export const applyRankingUpdate = (rankings: UserRanking[], update: RankingUpdate): UserRanking[] => {
+  const removed = new Set(update.removed ?? [])
+  const masked = rankings.filter((r) => r.user_id === 0)
+  const byUser = new Map(
+    rankings.filter((r) => r.user_id !== 0 && !removed.has(r.user_id)).map((r) => [r.user_id, r])
+  )
+  for (const row of update.changed ?? []) {
+    byUser.set(row.user_id, row)
+  }
+  return [...masked, ...byUser.values()].sort((a, b) => a.rank - b.rank)
 }
 
 export const rankingApi = {
-  getRankings: async (type: 'weekly' | 'monthly' | 'total' = 'weekly'): Promise<UserRanking[]> => {
-    const response = await apiClient.get<{ rankings: UserRanking[] }>('/api/v1/rankings', {
+  getRankings: async (
+    type: RankingPeriod = 'weekly',
+    metric: RankingMetric = 'minutes'
+  ): Promise<UserRanking[]> => {
+    const result = await rankingApi.getRankingResult(type, metric)
+    return result.rankings
+  },
+
+  getRankingResult: async (
+    type: RankingPeriod = 'weekly',
+    metric: RankingMetric = 'minutes',
+    options: RankingOptions = {}
+  ): Promise<RankingResult> => {
+    const response = await apiClient.get<RankingResult>('/api/v1/rankings', {
+      params: { type, metric, ...options },
+    })
+    return response.data
+  },
+
+  getSnapshotPeriods: async (type: SnapshotPeriod = 'weekly'): Promise<RankingRange[]> => {
+    const response = await apiClient.get<{ periods: RankingRange[] }>('/api/v1/rankings/history', {
       params: { type },
     })
-    return response.data.rankings
+    return response.data.periods
+  },
+
+  getSnapshot: async (
+    from: string,
+    type: SnapshotPeriod = 'weekly',
+    metric: RankingMetric = 'minutes'
+  ): Promise<RankingResult> => {
+    const response = await apiClient.get<RankingResult>(`/api/v1/rankings/history/${from}`, {
+      params: { type, metric },
+    })
+    return response.data
+  },
+
+  getUserHistory: async (
+    userId: number | 'me',
+    type: SnapshotPeriod = 'weekly',
+    metric: RankingMetric = 'minutes'
+  ): Promise<RankingHistoryEntry[]> => {
+    const response = await apiClient.get<{ history: RankingHistoryEntry[] }>(
+      `/api/v1/users/${userId}/ranking-history`,
+      { params: { type, metric } }
+    )
+    return response.data.history
   },
 }
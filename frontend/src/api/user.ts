@@ -4,6 +4,7 @@ import { User } from '../types'
 export interface UpdateProfileRequest {
   display_name: string
   is_presence_public: boolean
+  is_leaderboard_public?: boolean
 }
 
 export interface HeatmapData {
@@ -0,0 +1,40 @@
+import { useEffect } from 'react'
+import { useTranslation } from 'react-i18next'
+import { Trophy } from 'lucide-react'
+import { useOccupancyStore } from '../stores/useOccupancyStore'
+
+// 称号の解除を数秒間表示する（本人の解除と、設定により他のメンバーの解除）
+export const AchievementToast = ({ userId }: { userId: number }) => {
+    const { t } = useTranslation()
+    const { achievementUnlocked, clearAchievementUnlocked } = useOccupancyStore()
+
+    useEffect(() => {
+        if (!achievementUnlocked) return
+        const timer = setTimeout(clearAchievementUnlocked, 6000)
+        return () => clearTimeout(timer)
+    }, [achievementUnlocked, clearAchievementUnlocked])
+
+    if (!achievementUnlocked) return null
+
+    const isMine = achievementUnlocked.user_id === userId
+    const names = achievementUnlocked.achievements.map(a => a.name).join(', ')
+
+    return (
+        <div className="fixed bottom-6 right-6 z-50 max-w-sm bg-white border border-yellow-400 shadow-lg rounded-xl p-4 flex items-start space-x-3 text-left">
+            <div className="w-10 h-10 flex-shrink-0 rounded-full bg-gradient-to-br from-yellow-100 to-orange-100 text-yellow-600 flex items-center justify-center">
+                <Trophy size={22} strokeWidth={1.5} />
+            </div>
+            <div>
+                <p className="font-bold text-gray-900">
+                    {isMine
+                        ? t('achievements.toast_unlocked')
+                        : t('achievements.toast_announcement', { name: achievementUnlocked.display_name })}
+                </p>
+                <p className="text-sm text-gray-600">{names}</p>
+            </div>
+            <button onClick={clearAchievementUnlocked} className="text-gray-400 hover:text-gray-600 text-sm" aria-label={t('achievements.toast_close')}>
+                ×
+            </button>
+        </div>
+    )
+}
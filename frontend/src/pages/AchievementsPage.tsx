@@ -1,6 +1,6 @@
 import { useEffect, useState } from 'react'
 import { useTranslation } from 'react-i18next'
-import { achievementApi, Achievement, UserAchievement } from '../api/achievement'
+import { achievementApi, achievementIconSrc, Achievement, AchievementStatus, UserAchievement } from '../api/achievement'
 import { 
   Medal, 
   Lock, 
@@ -17,18 +17,21 @@ const AchievementsPage = () => {
   const { t } = useTranslation()
   const [achievements, setAchievements] = useState<Achievement[]>([])
   const [myAchievements, setMyAchievements] = useState<UserAchievement[]>([])
+  const [progress, setProgress] = useState<AchievementStatus[]>([])
   const [loading, setLoading] = useState(false)
 
   useEffect(() => {
     const fetchData = async () => {
       setLoading(true)
       try {
-        const [allData, myData] = await Promise.all([
+        const [allData, myData, progressData] = await Promise.all([
           achievementApi.getAchievements(),
           achievementApi.getMyAchievements(),
+          achievementApi.getMyProgress(),
         ])
         setAchievements(allData)
         setMyAchievements(myData)
+        setProgress(progressData)
       } catch (error) {
         console.error('Failed to fetch achievements:', error)
       } finally {
@@ -50,6 +53,14 @@ const AchievementsPage = () => {
     return null
   }
 
+  const getAwardNote = (achievementId: number) => {
+    return myAchievements.find((ua) => ua.achievement_id === achievementId)?.note
+  }
+
+  const getProgress = (achievementId: number) => {
+    return progress.find((p) => p.achievement.id === achievementId)
+  }
+
   const getAchievementIcon = (achievement: Achievement): LucideIcon => {
     const { code, category } = achievement
     
@@ -92,6 +103,7 @@ const AchievementsPage = () => {
           {achievements.map((ach) => {
             const achieved = isAchieved(ach.id)
             const IconComponent = getAchievementIcon(ach)
+            const status = getProgress(ach.id)
             
             return (
               <div
@@ -116,7 +128,9 @@ const AchievementsPage = () => {
                     w-12 h-12 flex-shrink-0 rounded-full flex items-center justify-center
                     ${achieved ? 'bg-gradient-to-br from-yellow-100 to-orange-100 text-yellow-600' : 'bg-gray-200 text-gray-400'}
                   `}>
-                    {achieved ? (
+                    {achieved && ach.icon_url ? (
+                      <img src={achievementIconSrc(ach.icon_url)} alt="" className="w-8 h-8 object-contain" />
+                    ) : achieved ? (
                       <IconComponent size={28} strokeWidth={1.5} />
                     ) : (
                       <Lock size={24} />
@@ -139,6 +153,20 @@ const AchievementsPage = () => {
                             </span>
                         )}
                     </div>
+                    {achieved && getAwardNote(ach.id) && (
+                      <p className="text-xs text-gray-500 mt-2">{getAwardNote(ach.id)}</p>
+                    )}
+                    {!achieved && status && status.target > 0 && ach.condition_type !== 'manual' && (
+                      <div className="mt-3">
+                        <div className="flex justify-between text-xs text-gray-500 mb-1">
+                          <span>{t('achievements.progress', { current: status.current, target: status.target })}</span>
+                          <span>{status.percent}%</span>
+                        </div>
+                        <div className="w-full bg-gray-200 rounded-full h-2">
+                          <div className="bg-primary-500 h-2 rounded-full" style={{ width: `${status.percent}%` }}></div>
+                        </div>
+                      </div>
+                    )}
                   </div>
                 </div>
               </div>
@@ -3,6 +3,7 @@ import { useTranslation } from 'react-i18next'
 import { apiClient } from '../api/client'
 import { AttendanceButton } from '../components/AttendanceButton'
 import { ActiveUsersList } from '../components/ActiveUsersList'
+import { AchievementToast } from '../components/AchievementToast'
 import { useOccupancyStore } from '../stores/useOccupancyStore'
 import { User } from '../types'
 
@@ -67,6 +68,7 @@ const HomePage = () => {
             </div>
             
             <ActiveUsersList />
+            <AchievementToast userId={user.id} />
           </div>
         ) : (
           <div className="bg-white rounded-xl shadow-xl p-8 max-w-lg mx-auto">
@@ -17,6 +17,7 @@ const ProfilePage = () => {
   // Form state
   const [displayName, setDisplayName] = useState('')
   const [isPresencePublic, setIsPresencePublic] = useState(true)
+  const [isLeaderboardPublic, setIsLeaderboardPublic] = useState(true)
   const [message, setMessage] = useState<{ text: string; type: 'success' | 'error' } | null>(null)
 
   useEffect(() => {
@@ -27,6 +28,7 @@ const ProfilePage = () => {
         setUser(user)
         setDisplayName(user.display_name)
         setIsPresencePublic(user.is_presence_public)
+        setIsLeaderboardPublic(user.is_leaderboard_public)
 
         // Fetch heatmap data
         const data = await userApi.getHeatmap('me')
@@ -49,6 +51,7 @@ const ProfilePage = () => {
       const req: UpdateProfileRequest = {
         display_name: displayName,
         is_presence_public: isPresencePublic,
+        is_leaderboard_public: isLeaderboardPublic,
       }
       const updatedUser = await userApi.updateProfile(req)
       setUser(updatedUser)
@@ -153,6 +156,23 @@ const ProfilePage = () => {
                 </div>
               </div>
 
+              <div className="flex items-start">
+                <div className="flex items-center h-5">
+                  <input
+                    id="is_leaderboard_public"
+                    name="is_leaderboard_public"
+                    type="checkbox"
+                    checked={isLeaderboardPublic}
+                    onChange={(e) => setIsLeaderboardPublic(e.target.checked)}
+                    className="focus:ring-primary-500 h-4 w-4 text-primary-600 border-gray-300 rounded"
+                  />
+                </div>
+                <div className="ml-3 text-sm">
+                  <label htmlFor="is_leaderboard_public" className="font-medium text-gray-700">{t('profile.public_leaderboard')}</label>
+                  <p className="text-gray-500">{t('profile.public_leaderboard_hint')}</p>
+                </div>
+              </div>
+
               {message && (
                 <div className={`p-3 rounded-md text-sm ${message.type === 'success' ? 'bg-green-50 text-green-700' : 'bg-red-50 text-red-700'}`}>
                   {message.text}
@@ -1,12 +1,25 @@
 import { useEffect, useState } from 'react'
 import { useTranslation } from 'react-i18next'
-import { rankingApi, UserRanking } from '../api/ranking'
+import { rankingApi, UserRanking, applyRankingUpdate } from '../api/ranking'
+import { useOccupancyStore } from '../stores/useOccupancyStore'
 
 const RankingPage = () => {
   const { t } = useTranslation()
   const [rankings, setRankings] = useState<UserRanking[]>([])
   const [loading, setLoading] = useState(false)
   const [period, setPeriod] = useState<'weekly' | 'monthly' | 'total'>('weekly')
+  // 増やすとランキングを取得し直す
+  const [reloadKey, setReloadKey] = useState(0)
+  const rankingUpdate = useOccupancyStore((state) => state.rankingUpdate)
+  const { connect, disconnect } = useOccupancyStore()
+
+  useEffect(() => {
+    // ranking_update イベントを受け取るため WebSocket に接続する
+    connect()
+    return () => {
+      disconnect()
+    }
+  }, [])
 
   useEffect(() => {
     const fetchRankings = async () => {
@@ -21,7 +34,20 @@ const RankingPage = () => {
       }
     }
     fetchRankings()
-  }, [period])
+  }, [period, reloadKey])
+
+  // チェックアウトで順位が変わったら、再読み込みせずに反映する
+  useEffect(() => {
+    if (!rankingUpdate || rankingUpdate.period !== period || rankingUpdate.metric !== 'minutes') {
+      return
+    }
+    if (rankingUpdate.masked) {
+      // 伏せられた行の変化は反映できないため取得し直す
+      setReloadKey((key) => key + 1)
+    } else {
+      setRankings((current) => applyRankingUpdate(current, rankingUpdate))
+    }
+  }, [rankingUpdate, period])
 
   const formatDuration = (minutes: number) => {
     const hours = Math.floor(minutes / 60)
@@ -81,7 +107,8 @@ const RankingPage = () => {
         ) : rankings.length > 0 ? (
           rankings.map((user, index) => (
             <div
-              key={user.user_id}
+              // 非公開ユーザーの行は user_id が 0 で返る
+              key={user.user_id || `private-${index}`}
               className={`
                 flex items-center justify-between p-4 rounded-xl border shadow-sm transition-all hover:shadow-md
                 ${getMedalColor(index)}
@@ -1,21 +1,34 @@
 import { create } from 'zustand'
 import { CheckInLog } from '../types'
 import { apiClient } from '../api/client'
+import { RankingUpdate } from '../api/ranking'
+import { AchievementUnlocked } from '../api/achievement'
 
 interface OccupancyState {
   activeUsers: CheckInLog[]
   isConnected: boolean
+  // 最後に受信した ranking_update イベント
+  rankingUpdate: RankingUpdate | null
+  // 最後に受信した称号の解除（本人の achievement_unlocked と他のメンバーの achievement_announcement）
+  achievementUnlocked: AchievementUnlocked | null
+  clearAchievementUnlocked: () => void
   fetchActiveUsers: () => Promise<void>
-  connect: () => void
+  connect: () => Promise<void>
   disconnect: () => void
 }
 
 export const useOccupancyStore = create<OccupancyState>((set, get) => {
   let socket: WebSocket | null = null
+  // チケットの取得中に connect が重ねて呼ばれないようにする
+  let connecting = false
 
   return {
     activeUsers: [],
     isConnected: false,
+    rankingUpdate: null,
+    achievementUnlocked: null,
+
+    clearAchievementUnlocked: () => set({ achievementUnlocked: null }),
 
     fetchActiveUsers: async () => {
       try {
@@ -27,14 +40,30 @@ export const useOccupancyStore = create<OccupancyState>((set, get) => {
       }
     },
 
-    connect: () => {
-      if (socket) return
+    connect: async () => {
+      if (socket || connecting) return
+      connecting = true
 
+      // JWT は URL に載せず（アクセスログに残るため）、使い捨てのチケットを取得して渡す
+      let ticket = ''
+      if (localStorage.getItem('token')) {
+        try {
+          const response = await apiClient.post<{ ticket: string }>('/api/v1/ws/ticket')
+          ticket = response.data.ticket
+        } catch (error) {
+          console.error('Failed to get WebSocket ticket', error)
+        }
+      }
+      // 取得中に disconnect された場合は接続しない
+      const cancelled = !connecting
+      connecting = false
+      if (cancelled || socket) return
 
       const apiBase = import.meta.env.VITE_API_BASE_URL || ''
-      const wsUrl = window.location.origin.replace(/^http/, 'ws') + apiBase + '/api/v1/ws'
+      const wsUrl = window.location.origin.replace(/^http/, 'ws') + apiBase + '/api/v1/ws' +
+        (ticket ? `?ticket=${encodeURIComponent(ticket)}` : '')
 
-      console.log('Connecting to WebSocket:', wsUrl)
+      console.log('Connecting to WebSocket')
       socket = new WebSocket(wsUrl)
 
       socket.onopen = () => {
@@ -71,6 +100,10 @@ export const useOccupancyStore = create<OccupancyState>((set, get) => {
                 return { activeUsers: [...others, payload] }
             } else if (type === 'check_out') {
                 return { activeUsers: state.activeUsers.filter(u => u.user_id !== payload.user_id) }
+            } else if (type === 'ranking_update') {
+                return { rankingUpdate: payload }
+            } else if (type === 'achievement_unlocked' || type === 'achievement_announcement') {
+                return { achievementUnlocked: payload }
             }
             return state
           })
@@ -81,6 +114,7 @@ export const useOccupancyStore = create<OccupancyState>((set, get) => {
     },
 
     disconnect: () => {
+      connecting = false
       if (socket) {
         socket.close()
         socket = null
@@ -6,6 +6,8 @@ export interface User {
   email?: string
   role: 'student' | 'teacher' | 'admin'
   is_presence_public: boolean
+  is_leaderboard_public: boolean
+  profile_visibility: 'public' | 'members' | 'private'
   created_at: string
   updated_at: string
   last_login_at?: string
@@ -23,6 +25,7 @@ export interface CheckInLog {
   wifi_ssid?: string
   gps_latitude?: number
   gps_longitude?: number
+  is_auto_checkout: boolean
   created_at: string
   updated_at: string
   user?: User